@@ -0,0 +1,14 @@
+resource "mongodb-users_rotating_user" "app" {
+  name         = "app"
+  db           = "test"
+  grace_period = "24h"
+  password_keeper = {
+    rotation = "2024-01"
+  }
+  roles = [
+    {
+      db   = "test"
+      role = "readWrite"
+    }
+  ]
+}
@@ -0,0 +1,92 @@
+package provider
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// userAlreadyExistsCode is the error code createUser returns for existing
+// users.
+const userAlreadyExistsCode = 51003
+
+// isUserAlreadyExists reports whether err is the createUser error for an
+// existing user.
+func isUserAlreadyExists(err error) bool {
+	var commandErr mongo.CommandError
+	return errors.As(err, &commandErr) && commandErr.Code == userAlreadyExistsCode
+}
+
+// adoptExisting reports whether Create adopts an existing user, adopt_existing
+// overrides the provider default.
+func (r *userResource) adoptExisting(plan userResourceModel) bool {
+	if !plan.AdoptExisting.IsNull() {
+		return plan.AdoptExisting.ValueBool()
+	}
+
+	return r.adoptExistingDefault
+}
+
+// adoptUser reconciles an existing user with the fields of the createUser
+// command that failed for it, sending them with updateUser instead.
+func (r *userResource) adoptUser(ctx context.Context, plan userResourceModel, createCommand bson.D) diag.Diagnostics {
+	var diags diag.Diagnostics
+	db, user := plan.Db.ValueString(), plan.User.ValueString()
+
+	current, err := r.getUserFromDb(ctx, db, user)
+	if err != nil {
+		diags.AddError(
+			"Error reading user from MongoDb",
+			"Could not retrieve user <"+user+"> "+err.Error())
+		return diags
+	}
+
+	_, diags = r.checkOwnership(current, plan.ForceAdopt.ValueBool(), "adopt")
+	if diags.HasError() {
+		return diags
+	}
+
+	err = r.runUserCommand(ctx, db, r.adoptCommand(plan, current, createCommand))
+	if err != nil {
+		diags.AddError(
+			"Error adopting user",
+			"Could not update existing user <"+user+">, unexpected error: "+err.Error(),
+		)
+		return diags
+	}
+
+	diags.AddWarning(
+		"User Adopted",
+		"User <"+user+"> already existed in database <"+db+"> and was adopted instead of created, "+
+			"its password, roles, authentication restrictions and custom data were replaced by the configuration.",
+	)
+
+	return diags
+}
+
+// adoptCommand builds the updateUser command adopting current from the
+// createUser command, keeping the creation time of its ownership marker.
+func (r *userResource) adoptCommand(plan userResourceModel, current dbUser, createCommand bson.D) bson.D {
+	updateCommand := bson.D{{Key: "updateUser", Value: plan.User.ValueString()}}
+	for _, element := range createCommand {
+		switch {
+		case element.Key == "createUser":
+			continue
+		// Unset roles and authentication restrictions are left to whatever
+		// else manages them.
+		case element.Key == "roles" && plan.Roles.IsNull():
+			continue
+		case element.Key == "authenticationRestrictions" && plan.AuthenticationRestrictions.IsNull():
+			continue
+		case element.Key == "customData":
+			element.Value = withProviderCustomData(documentToM(element.Value), bson.M{managedByCustomDataKey: r.managedByMarker(current.CustomData)})
+		}
+
+		updateCommand = append(updateCommand, element)
+	}
+
+	return updateCommand
+}
@@ -0,0 +1,166 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestIsUserAlreadyExists(t *testing.T) {
+	testCases := map[string]struct {
+		err      error
+		expected bool
+	}{
+		"nil": {
+			err: nil,
+		},
+		"other error": {
+			err: errors.New("connection refused"),
+		},
+		"other command error": {
+			err: mongo.CommandError{Code: 13, Message: "not authorized"},
+		},
+		"user already exists": {
+			err:      mongo.CommandError{Code: userAlreadyExistsCode, Message: "User \"test@test\" already exists"},
+			expected: true,
+		},
+		"wrapped": {
+			err:      fmt.Errorf("create: %w", mongo.CommandError{Code: userAlreadyExistsCode}),
+			expected: true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if actual := isUserAlreadyExists(testCase.err); actual != testCase.expected {
+				t.Errorf("expected %t, got %t", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestAdoptExisting(t *testing.T) {
+	testCases := map[string]struct {
+		adoptExisting types.Bool
+		providerValue bool
+		expected      bool
+	}{
+		"unset": {
+			adoptExisting: types.BoolNull(),
+		},
+		"provider default": {
+			adoptExisting: types.BoolNull(),
+			providerValue: true,
+			expected:      true,
+		},
+		"resource enabled": {
+			adoptExisting: types.BoolValue(true),
+			expected:      true,
+		},
+		"resource overrides provider": {
+			adoptExisting: types.BoolValue(false),
+			providerValue: true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			r := &userResource{adoptExistingDefault: testCase.providerValue}
+			actual := r.adoptExisting(userResourceModel{AdoptExisting: testCase.adoptExisting})
+			if actual != testCase.expected {
+				t.Errorf("expected %t, got %t", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestAdoptCommand(t *testing.T) {
+	r := &userResource{owner: "stack-a", version: "test"}
+	plan := userResourceModel{
+		User:                       types.StringValue("test"),
+		Roles:                      types.SetNull(types.ObjectType{}),
+		AuthenticationRestrictions: types.ListNull(types.ObjectType{}),
+	}
+	current := dbUser{User: "test", CustomData: bson.M{providerCustomDataKey: bson.M{managedByCustomDataKey: bson.M{
+		"owner":     "stack-b",
+		"createdAt": "2024-01-01T00:00:00Z",
+	}}}}
+	createCommand := bson.D{
+		{Key: "createUser", Value: "test"},
+		{Key: "pwd", Value: "abc123"},
+		{Key: "roles", Value: bson.A{}},
+		{Key: "customData", Value: withProviderCustomData(bson.M{"team": "a"}, bson.M{managedByCustomDataKey: r.managedByMarker(nil)})},
+	}
+
+	command := r.adoptCommand(plan, current, createCommand)
+
+	keys := []string{}
+	var customData bson.M
+	for _, element := range command {
+		keys = append(keys, element.Key)
+		if element.Key == "customData" {
+			customData = documentToM(element.Value)
+		}
+	}
+	if fmt.Sprint(keys) != "[updateUser pwd customData]" {
+		t.Errorf("expected updateUser, pwd and customData, got %v", keys)
+	}
+
+	marker := documentToM(providerCustomData(customData)[managedByCustomDataKey])
+	if customData["team"] != "a" || marker["owner"] != "stack-a" || marker["createdAt"] != "2024-01-01T00:00:00Z" {
+		t.Errorf("expected the configured custom data with the original creation time, got %v", customData)
+	}
+}
+
+func TestAccUserResourceAdoptExisting(t *testing.T) {
+	config := func(adoptExisting bool) string {
+		return providerConfig + fmt.Sprintf(`
+resource "mongodb-users_user" "test_adopt" {
+  user = "test_adopt"
+  db = "test"
+  password = "abc123"
+  adopt_existing = %t
+  roles = [
+    {
+      role = "readWrite"
+    }
+  ]
+}
+`, adoptExisting)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// An existing user fails the create
+			{
+				PreConfig: func() {
+					testAccMongoCommand(t, "test", bson.D{
+						{Key: "createUser", Value: "test_adopt"},
+						{Key: "pwd", Value: "old123"},
+						{Key: "roles", Value: bson.A{bson.M{"role": "read", "db": "test"}}},
+					})
+				},
+				Config:      config(false),
+				ExpectError: regexp.MustCompile("Error creating user at Mongo Level"),
+			},
+			// Unless it is adopted
+			{
+				Config: config(true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("mongodb-users_user.test_adopt", "roles.#", "1"),
+					resource.TestCheckTypeSetElemNestedAttrs("mongodb-users_user.test_adopt", "roles.*", map[string]string{
+						"role": "readWrite",
+						"db":   "test",
+					}),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,132 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type authenticationRestrictionModel struct {
+	ClientSource  types.List `tfsdk:"client_source"`
+	ServerAddress types.List `tfsdk:"server_address"`
+}
+
+// authenticationRestrictionObjectType is the element type of the
+// authentication_restrictions attribute.
+var authenticationRestrictionObjectType = types.ObjectType{
+	AttrTypes: map[string]attr.Type{
+		"client_source":  types.ListType{ElemType: types.StringType},
+		"server_address": types.ListType{ElemType: types.StringType},
+	},
+}
+
+type dbAuthenticationRestriction struct {
+	ClientSource  []string `bson:"clientSource,omitempty"`
+	ServerAddress []string `bson:"serverAddress,omitempty"`
+}
+
+// authenticationRestrictionsToBson converts restrictions to the document
+// array expected by the createUser and updateUser commands.
+func authenticationRestrictionsToBson(ctx context.Context, restrictions []authenticationRestrictionModel) (bson.A, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	result := bson.A{}
+	for _, restriction := range restrictions {
+		var document dbAuthenticationRestriction
+		diags.Append(restriction.ClientSource.ElementsAs(ctx, &document.ClientSource, false)...)
+		diags.Append(restriction.ServerAddress.ElementsAs(ctx, &document.ServerAddress, false)...)
+		result = append(result, document)
+	}
+
+	return result, diags
+}
+
+// authenticationRestrictionsFromDb converts the restrictions returned by
+// usersInfo to their Terraform representation.
+func authenticationRestrictionsFromDb(restrictions []dbAuthenticationRestriction) ([]authenticationRestrictionModel, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	result := []authenticationRestrictionModel{}
+	for _, restriction := range restrictions {
+		model := authenticationRestrictionModel{
+			ClientSource:  types.ListNull(types.StringType),
+			ServerAddress: types.ListNull(types.StringType),
+		}
+
+		if len(restriction.ClientSource) > 0 {
+			clientSource, d := types.ListValueFrom(context.Background(), types.StringType, restriction.ClientSource)
+			diags.Append(d...)
+			model.ClientSource = clientSource
+		}
+
+		if len(restriction.ServerAddress) > 0 {
+			serverAddress, d := types.ListValueFrom(context.Background(), types.StringType, restriction.ServerAddress)
+			diags.Append(d...)
+			model.ServerAddress = serverAddress
+		}
+
+		result = append(result, model)
+	}
+
+	return result, diags
+}
+
+// authenticationRestrictionsFromList converts an authentication_restrictions
+// list to the restrictions it contains. Null and unknown lists convert to
+// nil, an empty list to an empty slice.
+func authenticationRestrictionsFromList(ctx context.Context, restrictions types.List) ([]authenticationRestrictionModel, diag.Diagnostics) {
+	if restrictions.IsNull() || restrictions.IsUnknown() {
+		return nil, nil
+	}
+
+	result := []authenticationRestrictionModel{}
+	diags := restrictions.ElementsAs(ctx, &result, false)
+
+	return result, diags
+}
+
+// authenticationRestrictionsToList converts the restrictions returned by
+// usersInfo to an authentication_restrictions list.
+func authenticationRestrictionsToList(ctx context.Context, restrictions []dbAuthenticationRestriction) (types.List, diag.Diagnostics) {
+	models, diags := authenticationRestrictionsFromDb(restrictions)
+	if diags.HasError() {
+		return types.ListNull(authenticationRestrictionObjectType), diags
+	}
+
+	result, d := types.ListValueFrom(ctx, authenticationRestrictionObjectType, models)
+	diags.Append(d...)
+
+	return result, diags
+}
+
+// authenticationRestricted reports whether restrictions may hold at least one
+// restriction, unknown lists are assumed to.
+func authenticationRestricted(restrictions types.List) bool {
+	if restrictions.IsUnknown() {
+		return true
+	}
+
+	return !restrictions.IsNull() && len(restrictions.Elements()) > 0
+}
+
+// authenticationRestrictionsChanged reports whether the planned restrictions
+// differ from the ones in state.
+func authenticationRestrictionsChanged(ctx context.Context, state types.List, plan types.List) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	stateRestrictions, d := authenticationRestrictionsFromList(ctx, state)
+	diags.Append(d...)
+	planRestrictions, d := authenticationRestrictionsFromList(ctx, plan)
+	diags.Append(d...)
+
+	current, d := authenticationRestrictionsToBson(ctx, stateRestrictions)
+	diags.Append(d...)
+	planned, d := authenticationRestrictionsToBson(ctx, planRestrictions)
+	diags.Append(d...)
+
+	return !reflect.DeepEqual(current, planned), diags
+}
@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestAuthenticationRestrictionsRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	restrictions := []dbAuthenticationRestriction{
+		{ClientSource: []string{"10.0.0.0/8", "192.168.1.10"}},
+		{ClientSource: []string{"172.16.0.0/12"}, ServerAddress: []string{"10.1.0.1"}},
+	}
+
+	models, diags := authenticationRestrictionsFromDb(restrictions)
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	if !models[0].ServerAddress.IsNull() {
+		t.Errorf("expected missing serverAddress to be null, got %v", models[0].ServerAddress)
+	}
+
+	actual, diags := authenticationRestrictionsToBson(ctx, models)
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	expected := bson.A{restrictions[0], restrictions[1]}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+
+	state, diags := authenticationRestrictionsToList(ctx, restrictions)
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	plan, diags := authenticationRestrictionsToList(ctx, restrictions[:1])
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	changed, diags := authenticationRestrictionsChanged(ctx, state, plan)
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	if !changed {
+		t.Error("expected removed restriction to be a change")
+	}
+
+	changed, diags = authenticationRestrictionsChanged(ctx, state, state)
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	if changed {
+		t.Error("expected identical restrictions not to be a change")
+	}
+}
+
+func TestAuthenticationRestricted(t *testing.T) {
+	restriction := types.ObjectValueMust(authenticationRestrictionObjectType.AttrTypes, map[string]attr.Value{
+		"client_source":  types.ListValueMust(types.StringType, []attr.Value{types.StringValue("10.0.0.0/8")}),
+		"server_address": types.ListNull(types.StringType),
+	})
+
+	testCases := map[string]struct {
+		restrictions types.List
+		expected     bool
+	}{
+		"null": {
+			restrictions: types.ListNull(authenticationRestrictionObjectType),
+		},
+		"empty": {
+			restrictions: types.ListValueMust(authenticationRestrictionObjectType, []attr.Value{}),
+		},
+		"unknown": {
+			restrictions: types.ListUnknown(authenticationRestrictionObjectType),
+			expected:     true,
+		},
+		"restricted": {
+			restrictions: types.ListValueMust(authenticationRestrictionObjectType, []attr.Value{restriction}),
+			expected:     true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if actual := authenticationRestricted(testCase.restrictions); actual != testCase.expected {
+				t.Errorf("expected %t, got %t", testCase.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package provider
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// builtinRolesJSON is the catalog of MongoDB built-in roles, update it and
+// its mongodbVersion when MongoDB adds or scopes built-in roles.
+//
+//go:embed builtin_roles.json
+var builtinRolesJSON []byte
+
+// anyDatabase marks built-in roles that exist in every database.
+const anyDatabase = "*"
+
+type builtinRoleCatalog struct {
+	MongodbVersion string              `json:"mongodbVersion"`
+	Roles          map[string][]string `json:"roles"`
+}
+
+// builtinRoles is the parsed catalog of built-in roles.
+var builtinRoles = mustParseBuiltinRoles(builtinRolesJSON)
+
+func mustParseBuiltinRoles(data []byte) builtinRoleCatalog {
+	var catalog builtinRoleCatalog
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		panic("invalid built-in role catalog: " + err.Error())
+	}
+
+	return catalog
+}
+
+// validDatabases returns the databases a built-in role exists in, and false
+// when role is not a built-in role.
+func (c builtinRoleCatalog) validDatabases(role string) ([]string, bool) {
+	databases, ok := c.Roles[role]
+	return databases, ok
+}
+
+// allowedIn reports whether the built-in role exists in db.
+func (c builtinRoleCatalog) allowedIn(role string, db string) bool {
+	databases, _ := c.validDatabases(role)
+	for _, database := range databases {
+		if database == anyDatabase || database == db {
+			return true
+		}
+	}
+
+	return false
+}
+
+// closest returns the built-in role that role is most likely a typo of, and
+// false when no built-in role is close enough.
+func (c builtinRoleCatalog) closest(role string) (string, bool) {
+	names := make([]string, 0, len(c.Roles))
+	for name := range c.Roles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Allow more edits in longer names, short custom role names such as
+	// "reader" are otherwise flagged as typos of "read".
+	maxDistance := 1
+	if len(role) >= 8 {
+		maxDistance = 2
+	}
+
+	best, bestDistance := "", maxDistance+1
+	for _, name := range names {
+		distance := editDistance(strings.ToLower(role), strings.ToLower(name))
+		if distance < bestDistance {
+			best, bestDistance = name, distance
+		}
+	}
+
+	return best, best != ""
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
+
+var _ validator.Set = builtinRolesValidator{}
+
+// builtinRolesValidator checks roles against the built-in role catalog,
+// rejecting built-in roles granted on a database they do not exist in and
+// warning about likely typos of built-in role names.
+type builtinRolesValidator struct{}
+
+func (v builtinRolesValidator) Description(_ context.Context) string {
+	return fmt.Sprintf("built-in roles must be granted on a database they exist in, as of MongoDB %s", builtinRoles.MongodbVersion)
+}
+
+func (v builtinRolesValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v builtinRolesValidator) ValidateSet(ctx context.Context, req validator.SetRequest, resp *validator.SetResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	// Roles without db are granted on the database of the user.
+	var userDb types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("db"), &userDb)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for _, element := range req.ConfigValue.Elements() {
+		configRole, ok := roleFromValue(element)
+		if !ok {
+			continue
+		}
+
+		role, db := configRole.Role, configRole.Db
+		if db.IsNull() {
+			db = userDb
+		}
+		if role.IsUnknown() || role.IsNull() {
+			continue
+		}
+
+		elementPath := req.Path.AtSetValue(element)
+		name := role.ValueString()
+
+		if databases, ok := builtinRoles.validDatabases(name); ok {
+			if !db.IsUnknown() && !builtinRoles.allowedIn(name, db.ValueString()) {
+				resp.Diagnostics.AddAttributeError(
+					elementPath.AtName("db"),
+					"Invalid Built-in Role Database",
+					fmt.Sprintf("The built-in role %q only exists in the %s database, got %q. Set db = %q or use a database scoped role such as \"read\" or \"readWrite\".",
+						name, strings.Join(databases, ", "), db.ValueString(), databases[0]),
+				)
+			}
+			continue
+		}
+
+		if closest, ok := builtinRoles.closest(name); ok {
+			resp.Diagnostics.AddAttributeWarning(
+				elementPath.AtName("role"),
+				"Unknown Built-in Role",
+				fmt.Sprintf("The role %q is not a built-in role of MongoDB %s, did you mean %q? Ignore this warning if it is a custom role.",
+					name, builtinRoles.MongodbVersion, closest),
+			)
+		}
+	}
+}
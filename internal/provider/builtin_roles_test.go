@@ -0,0 +1,130 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestBuiltinRolesAllowedIn(t *testing.T) {
+	testCases := []struct {
+		role     string
+		db       string
+		expected bool
+	}{
+		{"read", "app", true},
+		{"readWrite", "admin", true},
+		{"readAnyDatabase", "admin", true},
+		{"readAnyDatabase", "app", false},
+		{"clusterMonitor", "app", false},
+		{"appReader", "app", false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.role+"@"+testCase.db, func(t *testing.T) {
+			if actual := builtinRoles.allowedIn(testCase.role, testCase.db); actual != testCase.expected {
+				t.Errorf("expected %t, got %t", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestBuiltinRolesClosest(t *testing.T) {
+	testCases := map[string]string{
+		"readwrite":           "readWrite",
+		"readWirte":           "readWrite",
+		"clustermonitor":      "clusterMonitor",
+		"readAnyDatabses":     "readAnyDatabase",
+		"userAdminAnyDb":      "",
+		"reader":              "",
+		"appReadOnly":         "",
+		"dbOwnr":              "dbOwner",
+		"userAdminAnyDatabse": "userAdminAnyDatabase",
+	}
+
+	for role, expected := range testCases {
+		t.Run(role, func(t *testing.T) {
+			actual, ok := builtinRoles.closest(role)
+			if ok != (expected != "") || actual != expected {
+				t.Errorf("expected %q, got %q", expected, actual)
+			}
+		})
+	}
+}
+
+func TestBuiltinRolesValidator(t *testing.T) {
+	ctx := context.Background()
+
+	var schemaResp resource.SchemaResponse
+	(&userResource{}).Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+
+	role := func(db attr.Value, name string) attr.Value {
+		return types.ObjectValueMust(roleObjectType.AttrTypes, map[string]attr.Value{
+			"db":   db,
+			"role": types.StringValue(name),
+		})
+	}
+
+	testCases := map[string]struct {
+		roles    []attr.Value
+		errors   int
+		warnings int
+	}{
+		"valid": {
+			roles:  []attr.Value{role(types.StringNull(), "readWrite"), role(types.StringValue("admin"), "clusterMonitor")},
+			errors: 0,
+		},
+		"admin only role on user db": {
+			roles:  []attr.Value{role(types.StringNull(), "readAnyDatabase")},
+			errors: 1,
+		},
+		"admin only role on other db": {
+			roles:  []attr.Value{role(types.StringValue("app"), "userAdminAnyDatabase")},
+			errors: 1,
+		},
+		"unknown db": {
+			roles:  []attr.Value{role(types.StringUnknown(), "clusterMonitor")},
+			errors: 0,
+		},
+		"typo": {
+			roles:    []attr.Value{role(types.StringNull(), "readWirte")},
+			warnings: 1,
+		},
+		"custom role": {
+			roles: []attr.Value{role(types.StringNull(), "appReadOnly")},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			objectType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+			values := map[string]tftypes.Value{}
+			for attribute, attributeType := range objectType.AttributeTypes {
+				values[attribute] = tftypes.NewValue(attributeType, nil)
+			}
+			values["db"] = tftypes.NewValue(tftypes.String, "app")
+
+			config := tfsdk.Config{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, values)}
+			resp := &validator.SetResponse{}
+			builtinRolesValidator{}.ValidateSet(ctx, validator.SetRequest{
+				Path:        path.Root("roles"),
+				Config:      config,
+				ConfigValue: types.SetValueMust(roleObjectType, testCase.roles),
+			}, resp)
+
+			if errors := resp.Diagnostics.ErrorsCount(); errors != testCase.errors {
+				t.Errorf("expected %d errors, got %d: %v", testCase.errors, errors, resp.Diagnostics)
+			}
+			if warnings := resp.Diagnostics.WarningsCount(); warnings != testCase.warnings {
+				t.Errorf("expected %d warnings, got %d: %v", testCase.warnings, warnings, resp.Diagnostics)
+			}
+		})
+	}
+}
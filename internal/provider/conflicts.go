@@ -0,0 +1,106 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+const (
+	// conflictPolicyFail fails updates of users changed outside of
+	// Terraform since the last refresh, the default.
+	conflictPolicyFail = "fail"
+	// conflictPolicyOverwrite overwrites changes made outside of Terraform.
+	conflictPolicyOverwrite = "overwrite"
+)
+
+// userConflicts compares the user currently in MongoDB with the prior state
+// and describes every change made by another actor since the last refresh.
+func userConflicts(ctx context.Context, state userResourceModel, current dbUser) ([]string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var conflicts []string
+
+	// Unset roles are managed elsewhere, changes to them are expected.
+	if !state.Roles.IsNull() {
+		currentRoles, d := rolesToSet(ctx, current.Roles)
+		diags.Append(d...)
+		if !currentRoles.Equal(state.Roles) {
+			stateRoles, d := rolesFromSet(ctx, state.Roles)
+			diags.Append(d...)
+			conflicts = append(conflicts, fmt.Sprintf("roles changed from [%s] to [%s]", formatRoles(stateRoles), formatDbRoles(current.Roles)))
+		}
+	}
+
+	if !state.AuthenticationRestrictions.IsNull() {
+		currentRestrictions, d := authenticationRestrictionsToList(ctx, current.AuthenticationRestrictions)
+		diags.Append(d...)
+		restrictionsChanged, d := authenticationRestrictionsChanged(ctx, state.AuthenticationRestrictions, currentRestrictions)
+		diags.Append(d...)
+		if restrictionsChanged {
+			conflicts = append(conflicts, "authentication_restrictions changed")
+		}
+	}
+
+	currentCustomData, currentLabels, d := customDataFromDb(ctx, current.CustomData, state)
+	diags.Append(d...)
+	if !state.CustomData.IsNull() || !currentCustomData.IsNull() {
+		equal, d := state.CustomData.StringSemanticEquals(ctx, currentCustomData)
+		diags.Append(d...)
+		if !equal {
+			conflicts = append(conflicts, fmt.Sprintf("custom_data changed from %s to %s", state.CustomData.String(), currentCustomData.String()))
+		}
+	}
+	if !currentLabels.Equal(state.Labels) {
+		conflicts = append(conflicts, fmt.Sprintf("labels changed from %s to %s", state.Labels.String(), currentLabels.String()))
+	}
+
+	return conflicts, diags
+}
+
+// checkConflicts fails the update when the user was changed outside of
+// Terraform since the last refresh, unless conflict_policy is "overwrite".
+func checkConflicts(ctx context.Context, plan userResourceModel, state userResourceModel, current dbUser) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if plan.ConflictPolicy.ValueString() == conflictPolicyOverwrite {
+		return diags
+	}
+
+	conflicts, diags := userConflicts(ctx, state, current)
+	if diags.HasError() || len(conflicts) == 0 {
+		return diags
+	}
+
+	diags.AddAttributeError(
+		path.Root("conflict_policy"),
+		"Conflicting User Changes",
+		"User <"+plan.User.ValueString()+"> was changed outside of Terraform since it was last refreshed:\n  - "+
+			strings.Join(conflicts, "\n  - ")+
+			"\n\nRefresh and review the plan again, or set conflict_policy = \""+conflictPolicyOverwrite+"\" to overwrite the changes.",
+	)
+
+	return diags
+}
+
+func formatRoles(roles []userRoleModel) string {
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Role.ValueString()+"@"+role.Db.ValueString())
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
+
+func formatDbRoles(roles []dbRole) string {
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Role+"@"+role.Db)
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
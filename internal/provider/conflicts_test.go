@@ -0,0 +1,110 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework-jsontypes/jsontypes"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestUserConflicts(t *testing.T) {
+	ctx := context.Background()
+
+	read := types.ObjectValueMust(roleObjectType.AttrTypes, map[string]attr.Value{
+		"db":   types.StringValue("test"),
+		"role": types.StringValue("read"),
+	})
+	state := userResourceModel{
+		Roles:                      types.SetValueMust(roleObjectType, []attr.Value{read}),
+		AuthenticationRestrictions: types.ListValueMust(authenticationRestrictionObjectType, []attr.Value{}),
+		CustomData:                 jsontypes.NewNormalizedValue(`{"team": "payments"}`),
+		Labels:                     types.MapNull(types.StringType),
+	}
+	unchanged := dbUser{
+		Roles: []dbRole{{Role: "read", Db: "test"}},
+		CustomData: bson.M{
+			"team":                "payments",
+			providerCustomDataKey: bson.M{"lastRotated": "2024-01-01T00:00:00Z"},
+		},
+	}
+
+	testCases := map[string]struct {
+		state     userResourceModel
+		current   dbUser
+		conflicts int
+	}{
+		"unchanged": {
+			state:   state,
+			current: unchanged,
+		},
+		"roles changed": {
+			state: state,
+			current: dbUser{
+				Roles:      []dbRole{{Role: "read", Db: "test"}, {Role: "dbAdmin", Db: "test"}},
+				CustomData: unchanged.CustomData,
+			},
+			conflicts: 1,
+		},
+		"unmanaged roles changed": {
+			state: userResourceModel{
+				Roles:                      types.SetNull(roleObjectType),
+				AuthenticationRestrictions: state.AuthenticationRestrictions,
+				CustomData:                 state.CustomData,
+				Labels:                     state.Labels,
+			},
+			current: dbUser{
+				Roles:      []dbRole{{Role: "dbAdmin", Db: "test"}},
+				CustomData: unchanged.CustomData,
+			},
+		},
+		"restrictions added": {
+			state: state,
+			current: dbUser{
+				Roles:                      unchanged.Roles,
+				CustomData:                 unchanged.CustomData,
+				AuthenticationRestrictions: []dbAuthenticationRestriction{{ClientSource: []string{"10.0.0.0/8"}}},
+			},
+			conflicts: 1,
+		},
+		"unmanaged restrictions changed": {
+			state: userResourceModel{
+				Roles:                      state.Roles,
+				AuthenticationRestrictions: types.ListNull(authenticationRestrictionObjectType),
+				CustomData:                 state.CustomData,
+				Labels:                     state.Labels,
+			},
+			current: dbUser{
+				Roles:                      unchanged.Roles,
+				CustomData:                 unchanged.CustomData,
+				AuthenticationRestrictions: []dbAuthenticationRestriction{{ClientSource: []string{"10.0.0.0/8"}}},
+			},
+		},
+		"custom data and labels changed": {
+			state: state,
+			current: dbUser{
+				Roles: unchanged.Roles,
+				CustomData: bson.M{
+					"team":              "billing",
+					labelsCustomDataKey: bson.M{"env": "prod"},
+				},
+			},
+			conflicts: 2,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			conflicts, diags := userConflicts(ctx, testCase.state, testCase.current)
+			if diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+
+			if len(conflicts) != testCase.conflicts {
+				t.Errorf("expected %d conflicts, got %q", testCase.conflicts, conflicts)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/hashicorp/terraform-plugin-framework-jsontypes/jsontypes"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	// providerCustomDataKey is the customData field holding the metadata the
+	// provider keeps on each user, other customData fields are left alone.
+	providerCustomDataKey = "terraform"
+
+	// labelsCustomDataKey is the customData field holding the labels
+	// attribute.
+	labelsCustomDataKey = "labels"
+)
+
+// providerCustomData returns the provider metadata stored in customData.
+func providerCustomData(customData bson.M) bson.M {
+	return documentToM(customData[providerCustomDataKey])
+}
+
+// withProviderCustomData returns a copy of customData with fields merged
+// into the provider metadata.
+func withProviderCustomData(customData bson.M, fields bson.M) bson.M {
+	metadata := bson.M{}
+	for key, value := range providerCustomData(customData) {
+		metadata[key] = value
+	}
+	for key, value := range fields {
+		metadata[key] = value
+	}
+
+	result := bson.M{}
+	for key, value := range customData {
+		result[key] = value
+	}
+	result[providerCustomDataKey] = metadata
+
+	return result
+}
+
+// setProviderCustomData merges fields into the provider metadata of an
+// existing user, keeping the rest of its customData.
+func (r *userResource) setProviderCustomData(ctx context.Context, db string, user string, fields bson.M) error {
+	current, err := r.getUserFromDb(ctx, db, user)
+	if err != nil {
+		return err
+	}
+
+	customDataCommand := bson.D{
+		{Key: "updateUser", Value: user},
+		{Key: "customData", Value: withProviderCustomData(current.CustomData, fields)},
+	}
+
+	return r.runUserCommand(ctx, db, customDataCommand)
+}
+
+// userCustomData builds the customData document for the custom_data and
+// labels attributes of model, keeping the provider metadata of current.
+func userCustomData(ctx context.Context, model userResourceModel, current bson.M) (bson.M, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	customData := bson.M{}
+	if !model.CustomData.IsNull() {
+		err := bson.UnmarshalExtJSON([]byte(model.CustomData.ValueString()), false, &customData)
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("custom_data"),
+				"Invalid Custom Data",
+				"Could not convert custom_data to a document, unexpected error: "+err.Error(),
+			)
+			return nil, diags
+		}
+	}
+
+	if !model.Labels.IsNull() {
+		labels := map[string]string{}
+		diags.Append(model.Labels.ElementsAs(ctx, &labels, false)...)
+		customData[labelsCustomDataKey] = labels
+	}
+
+	if metadata, ok := current[providerCustomDataKey]; ok {
+		customData[providerCustomDataKey] = metadata
+	}
+
+	return customData, diags
+}
+
+// customDataFromDb splits the customData returned by usersInfo into the
+// custom_data and labels attributes, prior is the model previously in state
+// and decides between null and empty values.
+func customDataFromDb(ctx context.Context, customData bson.M, prior userResourceModel) (jsontypes.Normalized, types.Map, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	document := bson.M{}
+	for key, value := range customData {
+		if key != providerCustomDataKey && key != labelsCustomDataKey {
+			document[key] = value
+		}
+	}
+
+	customDataValue := jsontypes.NewNormalizedNull()
+	if len(document) > 0 || !prior.CustomData.IsNull() {
+		data, err := bson.MarshalExtJSON(document, false, false)
+		if err == nil {
+			data, err = sortedJSON(data)
+		}
+		if err != nil {
+			diags.AddError(
+				"Error reading user custom data",
+				"Could not convert customData to JSON, unexpected error: "+err.Error(),
+			)
+			return customDataValue, types.MapNull(types.StringType), diags
+		}
+		customDataValue = jsontypes.NewNormalizedValue(string(data))
+	}
+
+	labels := map[string]string{}
+	for key, value := range documentToM(customData[labelsCustomDataKey]) {
+		if label, ok := value.(string); ok {
+			labels[key] = label
+		}
+	}
+
+	labelsValue := types.MapNull(types.StringType)
+	if len(labels) > 0 || !prior.Labels.IsNull() {
+		value, d := types.MapValueFrom(ctx, types.StringType, labels)
+		diags.Append(d...)
+		labelsValue = value
+	}
+
+	return customDataValue, labelsValue, diags
+}
+
+// sortedJSON re-encodes data with sorted object keys, as jsonencode does, so
+// imported custom_data matches configurations regardless of the field order
+// MongoDB returns.
+func sortedJSON(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	err := decoder.Decode(&value)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(value)
+}
+
+// documentToM converts a decoded embedded document to a bson.M, returning an
+// empty document for anything else.
+func documentToM(value interface{}) bson.M {
+	switch document := value.(type) {
+	case bson.M:
+		return document
+	case map[string]interface{}:
+		return document
+	case bson.D:
+		result := bson.M{}
+		for _, element := range document {
+			result[element.Key] = element.Value
+		}
+		return result
+	default:
+		return bson.M{}
+	}
+}
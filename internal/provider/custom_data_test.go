@@ -0,0 +1,121 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework-jsontypes/jsontypes"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestWithProviderCustomData(t *testing.T) {
+	testCases := map[string]struct {
+		customData bson.M
+		fields     bson.M
+		expected   bson.M
+	}{
+		"empty": {
+			fields:   bson.M{"lastRotated": "2024-01-01T00:00:00Z"},
+			expected: bson.M{providerCustomDataKey: bson.M{"lastRotated": "2024-01-01T00:00:00Z"}},
+		},
+		"keeps other fields": {
+			customData: bson.M{"team": "platform"},
+			fields:     bson.M{"lastRotated": "2024-01-01T00:00:00Z"},
+			expected: bson.M{
+				"team":                "platform",
+				providerCustomDataKey: bson.M{"lastRotated": "2024-01-01T00:00:00Z"},
+			},
+		},
+		"merges provider fields": {
+			customData: bson.M{providerCustomDataKey: bson.D{
+				{Key: "lastRotated", Value: "2024-01-01T00:00:00Z"},
+				{Key: "other", Value: "kept"},
+			}},
+			fields: bson.M{"lastRotated": "2024-02-01T00:00:00Z"},
+			expected: bson.M{providerCustomDataKey: bson.M{
+				"lastRotated": "2024-02-01T00:00:00Z",
+				"other":       "kept",
+			}},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual := withProviderCustomData(testCase.customData, testCase.fields)
+
+			if !reflect.DeepEqual(actual, testCase.expected) {
+				t.Errorf("expected %v, got %v", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestUserCustomData(t *testing.T) {
+	ctx := context.Background()
+	labels, _ := types.MapValueFrom(ctx, types.StringType, map[string]string{"team": "platform"})
+
+	model := userResourceModel{
+		CustomData: jsontypes.NewNormalizedValue(`{"ticket": "OPS-1", "cost": {"center": 42}}`),
+		Labels:     labels,
+	}
+	current := bson.M{providerCustomDataKey: bson.M{"lastRotated": "2024-01-01T00:00:00Z"}, "stale": true}
+
+	actual, diags := userCustomData(ctx, model, current)
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	expected := bson.M{
+		"ticket":              "OPS-1",
+		"cost":                bson.M{"center": int32(42)},
+		labelsCustomDataKey:   map[string]string{"team": "platform"},
+		providerCustomDataKey: bson.M{"lastRotated": "2024-01-01T00:00:00Z"},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestCustomDataFromDb(t *testing.T) {
+	ctx := context.Background()
+	customData := bson.M{
+		"ticket":              "OPS-1",
+		labelsCustomDataKey:   bson.M{"team": "platform"},
+		providerCustomDataKey: bson.M{"lastRotated": "2024-01-01T00:00:00Z"},
+	}
+	prior := userResourceModel{
+		CustomData: jsontypes.NewNormalizedNull(),
+		Labels:     types.MapNull(types.StringType),
+	}
+
+	customDataValue, labelsValue, diags := customDataFromDb(ctx, customData, prior)
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	equal, diags := customDataValue.StringSemanticEquals(ctx, jsontypes.NewNormalizedValue(`{ "ticket": "OPS-1" }`))
+	if diags.HasError() || !equal {
+		t.Errorf("expected custom data without reserved keys, got %s", customDataValue.ValueString())
+	}
+
+	labels := map[string]string{}
+	labelsValue.ElementsAs(ctx, &labels, false)
+	if !reflect.DeepEqual(labels, map[string]string{"team": "platform"}) {
+		t.Errorf("expected labels from customData, got %v", labels)
+	}
+
+	customDataValue, _, _ = customDataFromDb(ctx, bson.M{
+		"ticket": "OPS-1",
+		"cost":   bson.D{{Key: "owner", Value: "platform"}, {Key: "center", Value: int32(42)}},
+	}, prior)
+	if expected := `{"cost":{"center":42,"owner":"platform"},"ticket":"OPS-1"}`; customDataValue.ValueString() != expected {
+		t.Errorf("expected custom data %s as encoded by jsonencode, got %s", expected, customDataValue.ValueString())
+	}
+
+	customDataValue, labelsValue, _ = customDataFromDb(ctx, bson.M{}, prior)
+	if !customDataValue.IsNull() || !labelsValue.IsNull() {
+		t.Errorf("expected unset custom data to stay null, got %v and %v", customDataValue, labelsValue)
+	}
+}
@@ -0,0 +1,51 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+const (
+	// deletionPolicyDelete drops the user when the resource is destroyed,
+	// the default.
+	deletionPolicyDelete = "delete"
+	// deletionPolicyAbandon only removes the user from state, leaving it in
+	// MongoDB.
+	deletionPolicyAbandon = "abandon"
+)
+
+// deletionProtected fails the destroy or replacement of users with
+// deletion_protection.
+func deletionProtected(state userResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if state.DeletionProtection.ValueBool() {
+		diags.AddError(
+			"Deletion Protected",
+			"User <"+state.User.ValueString()+"> in database <"+state.Db.ValueString()+"> has deletion_protection set. "+
+				"Set deletion_protection to false and apply before destroying or replacing it.",
+		)
+	}
+
+	return diags
+}
+
+// checkDeletion fails the delete of users with deletion_protection and
+// reports whether the user is dropped, which deletion_policy abandon skips.
+// Protected users are normally rejected while planning already.
+func checkDeletion(state userResourceModel) (bool, diag.Diagnostics) {
+	diags := deletionProtected(state)
+	if diags.HasError() {
+		return false, diags
+	}
+
+	if state.DeletionPolicy.ValueString() == deletionPolicyAbandon {
+		diags.AddWarning(
+			"User Abandoned",
+			"User <"+state.User.ValueString()+"> was removed from state but left in database <"+state.Db.ValueString()+"> "+
+				"as deletion_policy is \""+deletionPolicyAbandon+"\".",
+		)
+		return false, diags
+	}
+
+	return true, diags
+}
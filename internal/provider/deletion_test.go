@@ -0,0 +1,118 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestCheckDeletion(t *testing.T) {
+	testCases := map[string]struct {
+		protection types.Bool
+		policy     types.String
+		drop       bool
+		err        bool
+		warning    bool
+	}{
+		"default": {
+			protection: types.BoolNull(),
+			policy:     types.StringNull(),
+			drop:       true,
+		},
+		"delete": {
+			protection: types.BoolValue(false),
+			policy:     types.StringValue(deletionPolicyDelete),
+			drop:       true,
+		},
+		"abandon": {
+			protection: types.BoolNull(),
+			policy:     types.StringValue(deletionPolicyAbandon),
+			warning:    true,
+		},
+		"protected": {
+			protection: types.BoolValue(true),
+			policy:     types.StringNull(),
+			err:        true,
+		},
+		"protected abandon": {
+			protection: types.BoolValue(true),
+			policy:     types.StringValue(deletionPolicyAbandon),
+			err:        true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			drop, diags := checkDeletion(userResourceModel{
+				User:               types.StringValue("test"),
+				Db:                 types.StringValue("test"),
+				DeletionProtection: testCase.protection,
+				DeletionPolicy:     testCase.policy,
+			})
+			if drop != testCase.drop {
+				t.Errorf("expected drop %t, got %t", testCase.drop, drop)
+			}
+			if diags.HasError() != testCase.err {
+				t.Errorf("expected error %t, got %v", testCase.err, diags)
+			}
+			if (diags.WarningsCount() > 0) != testCase.warning {
+				t.Errorf("expected warning %t, got %v", testCase.warning, diags)
+			}
+		})
+	}
+}
+
+func TestAccUserResourceDeletion(t *testing.T) {
+	config := func(user string, protection bool, policy string) string {
+		return providerConfig + fmt.Sprintf(`
+resource "mongodb-users_user" "test_deletion" {
+  user = %q
+  db = "test"
+  password = "abc123"
+  deletion_protection = %t
+  deletion_policy = %q
+  roles = [
+    {
+      role = "read"
+    }
+  ]
+}
+`, user, protection, policy)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		// The abandoned user is still there, dropUser fails otherwise
+		CheckDestroy: func(_ *terraform.State) error {
+			testAccMongoCommand(t, "test", bson.D{{Key: "dropUser", Value: "test_deletion"}})
+			return nil
+		},
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: config("test_deletion", true, deletionPolicyDelete),
+			},
+			// A protected user cannot be destroyed, which fails the plan
+			{
+				Config:      config("test_deletion", true, deletionPolicyDelete),
+				Destroy:     true,
+				ExpectError: regexp.MustCompile("Deletion Protected"),
+			},
+			// Nor replaced, which fails the plan as well
+			{
+				Config:      config("test_deletion_renamed", true, deletionPolicyDelete),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("Deletion Protected"),
+			},
+			// An abandoned user is destroyed by the end of the test but left in MongoDB
+			{
+				Config: config("test_deletion", false, deletionPolicyAbandon),
+			},
+		},
+	})
+}
@@ -0,0 +1,283 @@
+package provider
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// externalDb is the virtual database holding users that authenticate with
+// an external identity provider instead of a password.
+const externalDb = "$external"
+
+const (
+	externalIdentityLdap     = "ldap"
+	externalIdentityKerberos = "kerberos"
+	externalIdentityAwsIam   = "aws_iam"
+	externalIdentityOidc     = "oidc"
+	externalIdentityX509     = "x509"
+)
+
+// externalIdentityTypes are the accepted values of external_identity_type.
+var externalIdentityTypes = []string{
+	externalIdentityLdap,
+	externalIdentityKerberos,
+	externalIdentityAwsIam,
+	externalIdentityOidc,
+	externalIdentityX509,
+}
+
+var (
+	// awsIamArnRegex matches the ARNs of IAM users and roles.
+	awsIamArnRegex = regexp.MustCompile(`^arn:aws(-[a-z]+)*:iam::\d{12}:(user|role)/[\w+=,.@/-]+$`)
+
+	// kerberosPrincipalRegex matches primary[/instance]@REALM principals.
+	kerberosPrincipalRegex = regexp.MustCompile(`^[^/@\s]+(/[^/@\s]+)?@[A-Z0-9._-]+$`)
+
+	// oidcPrincipalRegex matches <authNamePrefix>/<principal> names.
+	oidcPrincipalRegex = regexp.MustCompile(`^[^/\s]+/\S+$`)
+)
+
+// validateExternalUser checks that user is well formed for identityType.
+func validateExternalUser(identityType string, user string) error {
+	switch identityType {
+	case externalIdentityAwsIam:
+		if !awsIamArnRegex.MatchString(user) {
+			return fmt.Errorf("expected the ARN of an IAM user or role such as \"arn:aws:iam::123456789012:role/app\", got %q", user)
+		}
+	case externalIdentityKerberos:
+		if !kerberosPrincipalRegex.MatchString(user) {
+			return fmt.Errorf("expected a Kerberos principal such as \"app/host.example.com@EXAMPLE.COM\", got %q", user)
+		}
+	case externalIdentityOidc:
+		if !oidcPrincipalRegex.MatchString(user) {
+			return fmt.Errorf("expected an OIDC principal prefixed with the authNamePrefix such as \"okta/app@example.com\", got %q", user)
+		}
+	case externalIdentityX509:
+		if _, err := parseDistinguishedName(user); err != nil {
+			return fmt.Errorf("expected an RFC 2253 subject distinguished name such as \"CN=app,OU=services,O=Example\": %w", err)
+		}
+	case externalIdentityLdap:
+		if strings.TrimSpace(user) != user || user == "" {
+			return fmt.Errorf("expected an LDAP username or distinguished name without surrounding whitespace, got %q", user)
+		}
+		if strings.Contains(user, "=") {
+			if _, err := parseDistinguishedName(user); err != nil {
+				return fmt.Errorf("expected an LDAP distinguished name such as \"cn=app,ou=users,dc=example,dc=com\": %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseDistinguishedName splits an RFC 2253 distinguished name into its
+// attribute type and value pairs, in the order they appear.
+func parseDistinguishedName(dn string) ([][2]string, error) {
+	var result [][2]string
+
+	var current strings.Builder
+	var parts []string
+	escaped := false
+	for _, c := range dn {
+		switch {
+		case escaped:
+			current.WriteRune('\\')
+			current.WriteRune(c)
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == ',' || c == '+':
+			parts = append(parts, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(c)
+		}
+	}
+	if escaped {
+		return nil, fmt.Errorf("distinguished name %q ends with an escape character", dn)
+	}
+	parts = append(parts, current.String())
+
+	for _, part := range parts {
+		attributeType, value, found := strings.Cut(part, "=")
+		attributeType = strings.TrimSpace(attributeType)
+		if !found || attributeType == "" || value == "" {
+			return nil, fmt.Errorf("distinguished name %q has an invalid attribute %q", dn, part)
+		}
+
+		result = append(result, [2]string{attributeType, value})
+	}
+
+	return result, nil
+}
+
+// distinguishedNameAttributes are the attribute type names MongoDB uses when
+// it renders the subject of a client certificate, other attribute types are
+// rendered as their dotted OID.
+var distinguishedNameAttributes = map[string]string{
+	"2.5.4.3":                    "CN",
+	"2.5.4.4":                    "SN",
+	"2.5.4.5":                    "serialNumber",
+	"2.5.4.6":                    "C",
+	"2.5.4.7":                    "L",
+	"2.5.4.8":                    "ST",
+	"2.5.4.9":                    "street",
+	"2.5.4.10":                   "O",
+	"2.5.4.11":                   "OU",
+	"2.5.4.12":                   "title",
+	"2.5.4.17":                   "postalCode",
+	"2.5.4.42":                   "GN",
+	"2.5.4.46":                   "dnQualifier",
+	"0.9.2342.19200300.100.1.1":  "UID",
+	"0.9.2342.19200300.100.1.25": "DC",
+	"1.2.840.113549.1.9.1":       "emailAddress",
+}
+
+// clusterMemberAttributes are the subject attributes MongoDB compares to
+// decide whether a certificate belongs to a cluster member.
+var clusterMemberAttributes = []string{"O", "OU", "DC"}
+
+// certificateSubject returns the RFC 2253 subject distinguished name of a
+// PEM encoded certificate, the form MongoDB uses as the name of X.509 users.
+// The relative distinguished names are kept in certificate order, reversed
+// as RFC 2253 requires, rather than the canonical order of pkix.Name.
+func certificateSubject(certificatePem string) (string, error) {
+	block, _ := pem.Decode([]byte(certificatePem))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return "", fmt.Errorf("expected a PEM encoded CERTIFICATE block")
+	}
+
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return "", err
+	}
+
+	var subject pkix.RDNSequence
+	_, err = asn1.Unmarshal(certificate.RawSubject, &subject)
+	if err != nil {
+		return "", err
+	}
+
+	if len(subject) == 0 {
+		return "", fmt.Errorf("the certificate has an empty subject")
+	}
+
+	rdns := make([]string, 0, len(subject))
+	for i := len(subject) - 1; i >= 0; i-- {
+		attributes := make([]string, 0, len(subject[i]))
+		for _, attribute := range subject[i] {
+			value, ok := attribute.Value.(string)
+			if !ok {
+				return "", fmt.Errorf("the subject attribute %s is not a string", attribute.Type)
+			}
+
+			name, ok := distinguishedNameAttributes[attribute.Type.String()]
+			if !ok {
+				name = attribute.Type.String()
+			}
+
+			attributes = append(attributes, name+"="+escapeDistinguishedNameValue(value))
+		}
+		rdns = append(rdns, strings.Join(attributes, "+"))
+	}
+
+	return strings.Join(rdns, ","), nil
+}
+
+// escapeDistinguishedNameValue escapes value as an RFC 2253 attribute value.
+func escapeDistinguishedNameValue(value string) string {
+	var escaped strings.Builder
+	for i, c := range value {
+		switch {
+		case strings.ContainsRune(",+\"<>;\\", c),
+			i == 0 && (c == ' ' || c == '#'),
+			i == len(value)-1 && c == ' ':
+			escaped.WriteRune('\\')
+		}
+		escaped.WriteRune(c)
+	}
+
+	return escaped.String()
+}
+
+// isClusterMemberSubject reports whether MongoDB would take a client
+// presenting a certificate for subject as a cluster member with a
+// certificate for memberSubject, their O, OU and DC attributes being equal.
+func isClusterMemberSubject(memberSubject string, subject string) (bool, error) {
+	memberAttributes, err := clusterMemberAttributeValues(memberSubject)
+	if err != nil {
+		return false, err
+	}
+
+	attributes, err := clusterMemberAttributeValues(subject)
+	if err != nil {
+		return false, err
+	}
+
+	return len(memberAttributes) > 0 && strings.Join(memberAttributes, "\n") == strings.Join(attributes, "\n"), nil
+}
+
+// clusterMemberAttributeValues returns the sorted O, OU and DC attributes of
+// a distinguished name.
+func clusterMemberAttributeValues(dn string) ([]string, error) {
+	attributes, err := parseDistinguishedName(dn)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []string
+	for _, attribute := range attributes {
+		for _, name := range clusterMemberAttributes {
+			if strings.EqualFold(attribute[0], name) {
+				values = append(values, name+"="+attribute[1])
+			}
+		}
+	}
+	sort.Strings(values)
+
+	return values, nil
+}
+
+// certificateSubjectModifier plans user as the subject of certificate_pem,
+// so a new certificate for the same subject keeps the user.
+type certificateSubjectModifier struct{}
+
+func (m certificateSubjectModifier) Description(_ context.Context) string {
+	return "Derives the user from the subject of certificate_pem."
+}
+
+func (m certificateSubjectModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m certificateSubjectModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	var certificatePem types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("certificate_pem"), &certificatePem)...)
+	if resp.Diagnostics.HasError() || certificatePem.IsNull() {
+		return
+	}
+
+	if certificatePem.IsUnknown() {
+		resp.PlanValue = types.StringUnknown()
+		return
+	}
+
+	subject, err := certificateSubject(certificatePem.ValueString())
+	if err != nil {
+		// Reported by ValidateConfig.
+		return
+	}
+
+	resp.PlanValue = types.StringValue(subject)
+}
@@ -0,0 +1,199 @@
+package provider
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testCertificatePem returns a self-signed PEM encoded certificate for
+// subject, serial changes between calls so rotations can be tested.
+func testCertificatePem(t *testing.T, subject pkix.RDNSequence) string {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("could not generate key: %s", err)
+	}
+
+	rawSubject, err := asn1.Marshal(subject)
+	if err != nil {
+		t.Fatalf("could not marshal subject: %s", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatalf("could not generate serial: %s", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		RawSubject:   rawSubject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("could not create certificate: %s", err)
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+// testSubject builds an RDN sequence in certificate order from type and
+// value pairs.
+func testSubject(attributes ...string) pkix.RDNSequence {
+	oids := map[string]asn1.ObjectIdentifier{
+		"C":  {2, 5, 4, 6},
+		"O":  {2, 5, 4, 10},
+		"OU": {2, 5, 4, 11},
+		"CN": {2, 5, 4, 3},
+		"DC": {0, 9, 2342, 19200300, 100, 1, 25},
+	}
+
+	var subject pkix.RDNSequence
+	for i := 0; i < len(attributes); i += 2 {
+		subject = append(subject, pkix.RelativeDistinguishedNameSET{
+			{Type: oids[attributes[i]], Value: attributes[i+1]},
+		})
+	}
+
+	return subject
+}
+
+func TestValidateExternalUser(t *testing.T) {
+	testCases := []struct {
+		identityType string
+		user         string
+		valid        bool
+	}{
+		{externalIdentityAwsIam, "arn:aws:iam::123456789012:role/app", true},
+		{externalIdentityAwsIam, "arn:aws:iam::123456789012:user/path/app", true},
+		{externalIdentityAwsIam, "arn:aws-us-gov:iam::123456789012:role/app", true},
+		{externalIdentityAwsIam, "arn:aws:iam::1234:role/app", false},
+		{externalIdentityAwsIam, "arn:aws:s3:::bucket", false},
+		{externalIdentityKerberos, "app@EXAMPLE.COM", true},
+		{externalIdentityKerberos, "app/host.example.com@EXAMPLE.COM", true},
+		{externalIdentityKerberos, "app", false},
+		{externalIdentityKerberos, "app@example.com", false},
+		{externalIdentityOidc, "okta/app@example.com", true},
+		{externalIdentityOidc, "app@example.com", false},
+		{externalIdentityX509, "CN=app,OU=services,O=Example", true},
+		{externalIdentityX509, "CN=app\\,1,O=Example", true},
+		{externalIdentityX509, "app", false},
+		{externalIdentityLdap, "app", true},
+		{externalIdentityLdap, "cn=app,ou=users,dc=example,dc=com", true},
+		{externalIdentityLdap, " app", false},
+		{externalIdentityLdap, "cn=app,,dc=com", false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.identityType+" "+testCase.user, func(t *testing.T) {
+			err := validateExternalUser(testCase.identityType, testCase.user)
+			if testCase.valid && err != nil {
+				t.Errorf("expected valid, got %s", err)
+			}
+			if !testCase.valid && err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestParseDistinguishedName(t *testing.T) {
+	actual, err := parseDistinguishedName("CN=app\\, inc,OU=services+O=Example")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := [][2]string{{"CN", "app\\, inc"}, {"OU", "services"}, {"O", "Example"}}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestCertificateSubject(t *testing.T) {
+	testCases := map[string]struct {
+		subject  pkix.RDNSequence
+		expected string
+	}{
+		"reversed certificate order": {
+			subject:  testSubject("C", "US", "O", "Example", "OU", "services", "CN", "app"),
+			expected: "CN=app,OU=services,O=Example,C=US",
+		},
+		"domain components": {
+			subject:  testSubject("DC", "com", "DC", "example", "CN", "app"),
+			expected: "CN=app,DC=example,DC=com",
+		},
+		"escaped values": {
+			subject:  testSubject("O", "Example, Inc.", "CN", " app+1"),
+			expected: "CN=\\ app\\+1,O=Example\\, Inc.",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := certificateSubject(testCertificatePem(t, testCase.subject))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if actual != testCase.expected {
+				t.Errorf("expected %q, got %q", testCase.expected, actual)
+			}
+		})
+	}
+
+	if _, err := certificateSubject("not a certificate"); err == nil {
+		t.Error("expected an error for an invalid certificate")
+	}
+}
+
+func TestIsClusterMemberSubject(t *testing.T) {
+	member := "CN=node1,OU=cluster,O=Example,DC=example,DC=com"
+
+	testCases := map[string]struct {
+		subject  string
+		expected bool
+	}{
+		"same O OU and DC": {
+			subject:  "CN=app,OU=cluster,O=Example,DC=example,DC=com",
+			expected: true,
+		},
+		"different OU": {
+			subject:  "CN=app,OU=services,O=Example,DC=example,DC=com",
+			expected: false,
+		},
+		"missing DC": {
+			subject:  "CN=app,OU=cluster,O=Example",
+			expected: false,
+		},
+		"attribute order": {
+			subject:  "CN=app,DC=example,DC=com,O=Example,OU=cluster",
+			expected: true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := isClusterMemberSubject(member, testCase.subject)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if actual != testCase.expected {
+				t.Errorf("expected %t, got %t", testCase.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// managedByCustomDataKey is the field of the provider metadata holding the
+// ownership marker stamped on created users.
+const managedByCustomDataKey = "managedBy"
+
+// managedByMarker returns the ownership marker of users managed by this
+// provider configuration. The creation time of a marker already in
+// customData is kept, so adopted users keep when they were created.
+func (r *userResource) managedByMarker(customData bson.M) bson.M {
+	createdAt, _ := documentToM(providerCustomData(customData)[managedByCustomDataKey])["createdAt"].(string)
+	if createdAt == "" {
+		createdAt = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	return bson.M{
+		"owner":           r.owner,
+		"providerVersion": r.version,
+		"createdAt":       createdAt,
+	}
+}
+
+// emptyOwnerWarning warns when user is stamped without an owner, every other
+// configuration without an owner may then change it.
+func (r *userResource) emptyOwnerWarning(user string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if r.owner != "" {
+		return diags
+	}
+
+	diags.AddWarning(
+		"User Stamped Without Owner",
+		"User <"+user+"> is stamped with an empty owner, so any other configuration without an owner can change or delete it. "+
+			"Set the owner provider attribute or MONGODB_OWNER to a name unique to this configuration.",
+	)
+
+	return diags
+}
+
+// managedByOwner returns the owner in the ownership marker of customData,
+// and false when the user has no marker, like users created before markers
+// were introduced.
+func managedByOwner(customData bson.M) (string, bool) {
+	marker, ok := providerCustomData(customData)[managedByCustomDataKey]
+	if !ok {
+		return "", false
+	}
+
+	owner, _ := documentToM(marker)["owner"].(string)
+
+	return owner, true
+}
+
+// checkOwnership fails when the current user is managed by another owner,
+// unless forceAdopt is set. It reports whether the marker of this owner has
+// to be stamped on the user, which claims unmarked users, like users created
+// before markers were introduced or imported ones, and adopted users.
+func (r *userResource) checkOwnership(current dbUser, forceAdopt bool, operation string) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	owner, marked := managedByOwner(current.CustomData)
+	if !marked {
+		return true, diags
+	}
+
+	if owner == r.owner {
+		return false, diags
+	}
+
+	if forceAdopt {
+		return true, diags
+	}
+
+	diags.AddError(
+		"User Managed By Another Owner",
+		fmt.Sprintf("Refusing to %s user <%s>, it is managed by owner %q while this provider is configured with owner %q. "+
+			"Remove it from one of the configurations, or set force_adopt to take it over.", operation, current.User, owner, r.owner),
+	)
+
+	return false, diags
+}
@@ -0,0 +1,221 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestManagedByOwner(t *testing.T) {
+	testCases := map[string]struct {
+		customData bson.M
+		owner      string
+		marked     bool
+	}{
+		"no custom data": {
+			customData: nil,
+		},
+		"no marker": {
+			customData: bson.M{providerCustomDataKey: bson.M{"lastRotated": "2024-01-01T00:00:00Z"}},
+		},
+		"marker": {
+			customData: bson.M{providerCustomDataKey: bson.M{managedByCustomDataKey: bson.M{"owner": "stack-a"}}},
+			owner:      "stack-a",
+			marked:     true,
+		},
+		"marker without owner": {
+			customData: bson.M{providerCustomDataKey: bson.D{{Key: managedByCustomDataKey, Value: bson.D{{Key: "providerVersion", Value: "dev"}}}}},
+			marked:     true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			owner, marked := managedByOwner(testCase.customData)
+			if owner != testCase.owner || marked != testCase.marked {
+				t.Errorf("expected (%q, %t), got (%q, %t)", testCase.owner, testCase.marked, owner, marked)
+			}
+		})
+	}
+}
+
+func TestCheckOwnership(t *testing.T) {
+	marked := func(owner string) dbUser {
+		return dbUser{User: "test", CustomData: bson.M{providerCustomDataKey: bson.M{managedByCustomDataKey: bson.M{"owner": owner}}}}
+	}
+
+	testCases := map[string]struct {
+		current    dbUser
+		forceAdopt bool
+		claim      bool
+		err        bool
+	}{
+		"unmarked": {
+			current: dbUser{User: "test"},
+			claim:   true,
+		},
+		"same owner": {
+			current: marked("stack-a"),
+		},
+		"other owner": {
+			current: marked("stack-b"),
+			err:     true,
+		},
+		"other owner adopted": {
+			current:    marked("stack-b"),
+			forceAdopt: true,
+			claim:      true,
+		},
+	}
+
+	r := &userResource{owner: "stack-a"}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			claim, diags := r.checkOwnership(testCase.current, testCase.forceAdopt, "update")
+			if claim != testCase.claim || diags.HasError() != testCase.err {
+				t.Errorf("expected (%t, error %t), got (%t, %v)", testCase.claim, testCase.err, claim, diags)
+			}
+		})
+	}
+}
+
+func TestManagedByMarker(t *testing.T) {
+	r := &userResource{owner: "stack-b", version: "test"}
+
+	marker := r.managedByMarker(bson.M{providerCustomDataKey: bson.M{managedByCustomDataKey: bson.M{
+		"owner":     "stack-a",
+		"createdAt": "2024-01-01T00:00:00Z",
+	}}})
+	if marker["owner"] != "stack-b" || marker["createdAt"] != "2024-01-01T00:00:00Z" {
+		t.Errorf("expected the new owner with the original creation time, got %v", marker)
+	}
+
+	marker = r.managedByMarker(nil)
+	if createdAt, _ := marker["createdAt"].(string); createdAt == "" || createdAt == "2024-01-01T00:00:00Z" {
+		t.Errorf("expected a new creation time, got %v", marker)
+	}
+}
+
+func TestEmptyOwnerWarning(t *testing.T) {
+	if diags := (&userResource{owner: "stack-a"}).emptyOwnerWarning("test"); len(diags) != 0 {
+		t.Errorf("expected no warning with an owner, got %v", diags)
+	}
+
+	if diags := (&userResource{}).emptyOwnerWarning("test"); diags.WarningsCount() != 1 {
+		t.Errorf("expected a warning without an owner, got %v", diags)
+	}
+}
+
+func TestAccUserResourceOwnership(t *testing.T) {
+	config := func(owner string, role string, forceAdopt bool) string {
+		return fmt.Sprintf(`
+provider "mongodb-users" {
+  host = "localhost:27017"
+  username = "root"
+  password = "password123"
+  owner = %q
+}
+
+resource "mongodb-users_user" "test_owner" {
+  user = "test_owner"
+  db = "test"
+  password = "abc123"
+  force_adopt = %t
+  roles = [
+    {
+      role = %q
+    }
+  ]
+}
+`, owner, forceAdopt, role)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: config("stack-a", "read", false),
+			},
+			// Another owner cannot change the user
+			{
+				Config:      config("stack-b", "readWrite", false),
+				ExpectError: regexp.MustCompile("User Managed By Another Owner"),
+			},
+			// Unless it adopts it
+			{
+				Config: config("stack-b", "readWrite", true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckTypeSetElemNestedAttrs("mongodb-users_user.test_owner", "roles.*", map[string]string{
+						"role": "readWrite",
+					}),
+				),
+			},
+			// The new owner manages it without force_adopt
+			{
+				Config: config("stack-b", "read", false),
+			},
+		},
+	})
+}
+
+func TestAccUserResourceOwnershipImported(t *testing.T) {
+	config := func(owner string, role string) string {
+		return fmt.Sprintf(`
+provider "mongodb-users" {
+  host = "localhost:27017"
+  username = "root"
+  password = "password123"
+  owner = %q
+}
+
+resource "mongodb-users_user" "test_owner_imported" {
+  user = "test_owner_imported"
+  db = "test"
+  password = "abc123"
+  roles = [
+    {
+      role = %q
+    }
+  ]
+}
+`, owner, role)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Import a user without ownership marker
+			{
+				PreConfig: func() {
+					testAccMongoCommand(t, "test", bson.D{
+						{Key: "createUser", Value: "test_owner_imported"},
+						{Key: "pwd", Value: "old123"},
+						{Key: "roles", Value: bson.A{bson.M{"role": "read", "db": "test"}}},
+					})
+				},
+				Config:             config("stack-a", "read"),
+				ResourceName:       "mongodb-users_user.test_owner_imported",
+				ImportState:        true,
+				ImportStateId:      "test.test_owner_imported",
+				ImportStatePersist: true,
+			},
+			// The first update claims it
+			{
+				Config: config("stack-a", "read"),
+			},
+			// Another owner cannot change it anymore
+			{
+				Config:      config("stack-b", "readWrite"),
+				ExpectError: regexp.MustCompile("User Managed By Another Owner"),
+			},
+			// The owner still manages it and destroys it
+			{
+				Config: config("stack-a", "read"),
+			},
+		},
+	})
+}
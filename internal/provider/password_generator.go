@@ -0,0 +1,252 @@
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+const (
+	lowerCharacters   = "abcdefghijklmnopqrstuvwxyz"
+	upperCharacters   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	numericCharacters = "0123456789"
+	specialCharacters = "!#$%&*()-_=+[]{}<>:?"
+)
+
+type passwordGeneratorModel struct {
+	Length            types.Int64  `tfsdk:"length"`
+	Lower             types.Bool   `tfsdk:"lower"`
+	Upper             types.Bool   `tfsdk:"upper"`
+	Numeric           types.Bool   `tfsdk:"numeric"`
+	Special           types.Bool   `tfsdk:"special"`
+	ExcludeCharacters types.String `tfsdk:"exclude_characters"`
+}
+
+// passwordGeneratorBlock returns the schema of the generate_password block.
+func passwordGeneratorBlock(description string) schema.SingleNestedBlock {
+	return schema.SingleNestedBlock{
+		Description: description,
+		Attributes: map[string]schema.Attribute{
+			"length": schema.Int64Attribute{
+				Description: "Length of the generated password, defaults to 32",
+				Optional:    true,
+				Validators: []validator.Int64{
+					int64validator.Between(8, 1024),
+				},
+			},
+			"lower": schema.BoolAttribute{
+				Description: "Include lowercase letters, defaults to true",
+				Optional:    true,
+			},
+			"upper": schema.BoolAttribute{
+				Description: "Include uppercase letters, defaults to true",
+				Optional:    true,
+			},
+			"numeric": schema.BoolAttribute{
+				Description: "Include digits, defaults to true",
+				Optional:    true,
+			},
+			"special": schema.BoolAttribute{
+				Description: "Include special characters `" + specialCharacters + "`, defaults to true",
+				Optional:    true,
+			},
+			"exclude_characters": schema.StringAttribute{
+				Description: "Characters that never appear in the generated password",
+				Optional:    true,
+			},
+		},
+	}
+}
+
+// validatePasswordGenerator checks that a configured generate_password block
+// can produce a password.
+func validatePasswordGenerator(ctx context.Context, generate types.Object) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if generate.IsNull() || generate.IsUnknown() {
+		return diags
+	}
+
+	var generator passwordGeneratorModel
+	diags.Append(generate.As(ctx, &generator, basetypes.ObjectAsOptions{})...)
+	if diags.HasError() {
+		return diags
+	}
+
+	known := !generator.Length.IsUnknown() && !generator.ExcludeCharacters.IsUnknown()
+	for _, enabled := range []types.Bool{generator.Lower, generator.Upper, generator.Numeric, generator.Special} {
+		known = known && !enabled.IsUnknown()
+	}
+
+	if known {
+		_, err := generator.withDefaults().characterClasses()
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("generate_password"),
+				"Invalid Password Generator",
+				"The generate_password settings cannot produce a password: "+err.Error(),
+			)
+		}
+	}
+
+	return diags
+}
+
+// withDefaults returns a copy of m with unset attributes set to their
+// defaults, all character classes and a length of 32.
+func (m passwordGeneratorModel) withDefaults() passwordGeneratorModel {
+	if m.Length.IsNull() {
+		m.Length = types.Int64Value(32)
+	}
+
+	for _, enabled := range []*types.Bool{&m.Lower, &m.Upper, &m.Numeric, &m.Special} {
+		if enabled.IsNull() {
+			*enabled = types.BoolValue(true)
+		}
+	}
+
+	return m
+}
+
+// characterClasses returns the enabled character classes with the excluded
+// characters removed.
+func (m passwordGeneratorModel) characterClasses() ([]string, error) {
+	var classes []string
+	for _, class := range []struct {
+		name       string
+		enabled    types.Bool
+		characters string
+	}{
+		{"lower", m.Lower, lowerCharacters},
+		{"upper", m.Upper, upperCharacters},
+		{"numeric", m.Numeric, numericCharacters},
+		{"special", m.Special, specialCharacters},
+	} {
+		if !class.enabled.ValueBool() {
+			continue
+		}
+
+		characters := strings.Map(func(c rune) rune {
+			if strings.ContainsRune(m.ExcludeCharacters.ValueString(), c) {
+				return -1
+			}
+			return c
+		}, class.characters)
+
+		if characters == "" {
+			return nil, fmt.Errorf("all %s characters are excluded", class.name)
+		}
+
+		classes = append(classes, characters)
+	}
+
+	if len(classes) == 0 {
+		return nil, fmt.Errorf("at least one character class must be enabled")
+	}
+
+	if int(m.Length.ValueInt64()) < len(classes) {
+		return nil, fmt.Errorf("length %d is shorter than the %d enabled character classes", m.Length.ValueInt64(), len(classes))
+	}
+
+	return classes, nil
+}
+
+// generate returns a password with at least one character of every enabled
+// character class, using crypto/rand for every choice.
+func (m passwordGeneratorModel) generate() (string, error) {
+	classes, err := m.characterClasses()
+	if err != nil {
+		return "", err
+	}
+
+	password := make([]byte, 0, m.Length.ValueInt64())
+	for _, class := range classes {
+		c, err := randomCharacter(class)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, c)
+	}
+
+	all := strings.Join(classes, "")
+	for len(password) < cap(password) {
+		c, err := randomCharacter(all)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, c)
+	}
+
+	// Shuffle so the guaranteed characters are not always at the front.
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j.Int64()] = password[j.Int64()], password[i]
+	}
+
+	return string(password), nil
+}
+
+func randomCharacter(characters string) (byte, error) {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(len(characters))))
+	if err != nil {
+		return 0, err
+	}
+
+	return characters[i.Int64()], nil
+}
+
+// generatedPasswordModifier keeps the generated password from state and only
+// plans a new one when the resource is created or password_keeper changes.
+type generatedPasswordModifier struct{}
+
+func (m generatedPasswordModifier) Description(_ context.Context) string {
+	return "Keeps the generated password unless password_keeper changes."
+}
+
+func (m generatedPasswordModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m generatedPasswordModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	var generate types.Object
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("generate_password"), &generate)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if generate.IsNull() {
+		resp.PlanValue = types.StringNull()
+		return
+	}
+
+	// Nothing generated yet, leave it unknown so it is generated on apply.
+	if req.StateValue.IsNull() {
+		return
+	}
+
+	var planKeeper, stateKeeper types.Map
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("password_keeper"), &planKeeper)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("password_keeper"), &stateKeeper)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !planKeeper.Equal(stateKeeper) {
+		return
+	}
+
+	resp.PlanValue = req.StateValue
+}
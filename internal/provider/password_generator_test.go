@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestPasswordGeneratorGenerate(t *testing.T) {
+	generator := passwordGeneratorModel{
+		Length:            types.Int64Value(12),
+		Lower:             types.BoolValue(true),
+		Upper:             types.BoolValue(true),
+		Numeric:           types.BoolValue(true),
+		Special:           types.BoolValue(false),
+		ExcludeCharacters: types.StringValue("0Oo1lI"),
+	}
+
+	for i := 0; i < 100; i++ {
+		password, err := generator.generate()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if len(password) != 12 {
+			t.Errorf("expected length 12, got %d", len(password))
+		}
+
+		if strings.ContainsAny(password, "0Oo1lI") {
+			t.Errorf("expected no excluded characters, got %q", password)
+		}
+
+		if strings.ContainsAny(password, specialCharacters) {
+			t.Errorf("expected no special characters, got %q", password)
+		}
+
+		for _, class := range []string{lowerCharacters, upperCharacters, numericCharacters} {
+			if !strings.ContainsAny(password, class) {
+				t.Errorf("expected a character of %q, got %q", class, password)
+			}
+		}
+	}
+}
+
+func TestPasswordGeneratorCharacterClasses(t *testing.T) {
+	testCases := map[string]passwordGeneratorModel{
+		"no classes": {
+			Length: types.Int64Value(16),
+		},
+		"all excluded": {
+			Length:            types.Int64Value(16),
+			Numeric:           types.BoolValue(true),
+			ExcludeCharacters: types.StringValue(numericCharacters),
+		},
+		"too short": {
+			Length:  types.Int64Value(1),
+			Lower:   types.BoolValue(true),
+			Numeric: types.BoolValue(true),
+		},
+	}
+
+	for name, generator := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := generator.characterClasses()
+			if err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestPasswordGeneratorWithDefaults(t *testing.T) {
+	generator := passwordGeneratorModel{
+		Length:  types.Int64Null(),
+		Lower:   types.BoolNull(),
+		Upper:   types.BoolNull(),
+		Numeric: types.BoolValue(false),
+		Special: types.BoolNull(),
+	}.withDefaults()
+
+	if generator.Length.ValueInt64() != 32 {
+		t.Errorf("expected default length 32, got %d", generator.Length.ValueInt64())
+	}
+
+	if !generator.Lower.ValueBool() || !generator.Upper.ValueBool() || !generator.Special.ValueBool() {
+		t.Error("expected unset character classes to be enabled")
+	}
+
+	if generator.Numeric.ValueBool() {
+		t.Error("expected disabled character class to stay disabled")
+	}
+}
@@ -0,0 +1,281 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+type passwordPolicyModel struct {
+	MinLength           types.Int64  `tfsdk:"min_length"`
+	RequireLower        types.Bool   `tfsdk:"require_lower"`
+	RequireUpper        types.Bool   `tfsdk:"require_upper"`
+	RequireNumeric      types.Bool   `tfsdk:"require_numeric"`
+	RequireSpecial      types.Bool   `tfsdk:"require_special"`
+	ForbiddenSubstrings types.List   `tfsdk:"forbidden_substrings"`
+	DenylistFile        types.String `tfsdk:"denylist_file"`
+}
+
+// passwordPolicy is the strength policy passwords of users have to meet,
+// MongoDB does not enforce any.
+type passwordPolicy struct {
+	minLength      int
+	requireLower   bool
+	requireUpper   bool
+	requireNumeric bool
+	requireSpecial bool
+	// forbiddenSubstrings and denylist are lowercase.
+	forbiddenSubstrings []string
+	denylist            map[string]bool
+}
+
+// passwordPolicyBlock returns the schema of the provider password_policy
+// block.
+func passwordPolicyBlock() schema.SingleNestedBlock {
+	return schema.SingleNestedBlock{
+		Description: "Strength policy for the passwords of users, checked while planning for password and generate_password and while applying for password_wo",
+		Attributes: map[string]schema.Attribute{
+			"min_length": schema.Int64Attribute{
+				Description: "Minimum length of passwords",
+				Optional:    true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"require_lower": schema.BoolAttribute{
+				Description: "Require a lowercase letter",
+				Optional:    true,
+			},
+			"require_upper": schema.BoolAttribute{
+				Description: "Require an uppercase letter",
+				Optional:    true,
+			},
+			"require_numeric": schema.BoolAttribute{
+				Description: "Require a digit",
+				Optional:    true,
+			},
+			"require_special": schema.BoolAttribute{
+				Description: "Require a character that is neither a letter nor a digit",
+				Optional:    true,
+			},
+			"forbidden_substrings": schema.ListAttribute{
+				Description: "Substrings passwords cannot contain, compared case-insensitively. The user and db names of the user are always forbidden, generated passwords containing any of them are regenerated",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"denylist_file": schema.StringAttribute{
+				Description: "Path to a local file of forbidden passwords, one per line and compared case-insensitively. Empty lines and lines starting with # are ignored",
+				Optional:    true,
+			},
+		},
+	}
+}
+
+// newPasswordPolicy converts the password_policy block and reads its
+// denylist, returning nil when it is not set.
+func newPasswordPolicy(ctx context.Context, policy types.Object) (*passwordPolicy, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if policy.IsNull() {
+		return nil, diags
+	}
+
+	if policy.IsUnknown() {
+		diags.AddAttributeError(
+			path.Root("password_policy"),
+			"Unknown Password Policy",
+			"The provider cannot enforce a password policy with unknown values, set the password_policy block statically in the configuration.",
+		)
+		return nil, diags
+	}
+
+	var model passwordPolicyModel
+	diags.Append(policy.As(ctx, &model, basetypes.ObjectAsOptions{})...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	result := &passwordPolicy{
+		minLength:      int(model.MinLength.ValueInt64()),
+		requireLower:   model.RequireLower.ValueBool(),
+		requireUpper:   model.RequireUpper.ValueBool(),
+		requireNumeric: model.RequireNumeric.ValueBool(),
+		requireSpecial: model.RequireSpecial.ValueBool(),
+		denylist:       map[string]bool{},
+	}
+
+	var substrings []string
+	diags.Append(model.ForbiddenSubstrings.ElementsAs(ctx, &substrings, false)...)
+	for _, substring := range substrings {
+		result.forbiddenSubstrings = append(result.forbiddenSubstrings, strings.ToLower(substring))
+	}
+
+	if !model.DenylistFile.IsNull() {
+		data, err := os.ReadFile(model.DenylistFile.ValueString())
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("password_policy").AtName("denylist_file"),
+				"Unreadable Password Denylist",
+				"Could not read the password denylist, unexpected error: "+err.Error(),
+			)
+			return nil, diags
+		}
+
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line != "" && !strings.HasPrefix(line, "#") {
+				result.denylist[strings.ToLower(line)] = true
+			}
+		}
+	}
+
+	return result, diags
+}
+
+// check returns an error listing every rule password breaks, nil when it
+// meets the policy. user and db are forbidden substrings as well.
+func (p *passwordPolicy) check(password string, user string, db string) error {
+	if p == nil {
+		return nil
+	}
+
+	var violations []string
+	if len(password) < p.minLength {
+		violations = append(violations, fmt.Sprintf("it is shorter than %d characters", p.minLength))
+	}
+
+	for _, class := range []struct {
+		required bool
+		name     string
+		matches  func(rune) bool
+	}{
+		{p.requireLower, "a lowercase letter", func(c rune) bool { return strings.ContainsRune(lowerCharacters, c) }},
+		{p.requireUpper, "an uppercase letter", func(c rune) bool { return strings.ContainsRune(upperCharacters, c) }},
+		{p.requireNumeric, "a digit", func(c rune) bool { return strings.ContainsRune(numericCharacters, c) }},
+		{p.requireSpecial, "a special character", func(c rune) bool {
+			return !strings.ContainsRune(lowerCharacters+upperCharacters+numericCharacters, c)
+		}},
+	} {
+		if class.required && !strings.ContainsFunc(password, class.matches) {
+			violations = append(violations, "it has no "+class.name)
+		}
+	}
+
+	lower := strings.ToLower(password)
+	for _, substring := range append([]string{strings.ToLower(user), strings.ToLower(db)}, p.forbiddenSubstrings...) {
+		if substring != "" && strings.Contains(lower, substring) {
+			violations = append(violations, fmt.Sprintf("it contains %q", substring))
+		}
+	}
+
+	if p.denylist[lower] {
+		violations = append(violations, "it is on the password denylist")
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("the password does not meet the provider password policy: %s", strings.Join(violations, ", "))
+	}
+
+	return nil
+}
+
+// checkGenerator returns an error when generator settings cannot meet the
+// policy, the generated password itself is checked once generated.
+func (p *passwordPolicy) checkGenerator(generator passwordGeneratorModel) error {
+	if p == nil {
+		return nil
+	}
+
+	generator = generator.withDefaults()
+
+	var violations []string
+	if int(generator.Length.ValueInt64()) < p.minLength {
+		violations = append(violations, fmt.Sprintf("length is shorter than %d", p.minLength))
+	}
+
+	for _, class := range []struct {
+		required bool
+		enabled  types.Bool
+		name     string
+	}{
+		{p.requireLower, generator.Lower, "lower"},
+		{p.requireUpper, generator.Upper, "upper"},
+		{p.requireNumeric, generator.Numeric, "numeric"},
+		{p.requireSpecial, generator.Special, "special"},
+	} {
+		if class.required && !class.enabled.ValueBool() {
+			violations = append(violations, class.name+" is required by the policy")
+		}
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("generate_password does not meet the provider password policy: %s", strings.Join(violations, ", "))
+	}
+
+	return nil
+}
+
+// checkPlannedPassword checks the password and generate_password settings of
+// a new or changed password while planning. Write-only passwords are not
+// available yet and are checked by enforcePasswordPolicy.
+func (r *userResource) checkPlannedPassword(ctx context.Context, plan userResourceModel, changed bool) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if r.passwordPolicy == nil {
+		return diags
+	}
+
+	if changed && !plan.Password.IsNull() && !plan.Password.IsUnknown() && !plan.User.IsUnknown() && !plan.Db.IsUnknown() {
+		err := r.passwordPolicy.check(plan.Password.ValueString(), plan.User.ValueString(), plan.Db.ValueString())
+		if err != nil {
+			diags.AddAttributeError(path.Root("password"), "Password Policy Violation", err.Error())
+		}
+	}
+
+	if !plan.GeneratePassword.IsNull() && !plan.GeneratePassword.IsUnknown() {
+		var generator passwordGeneratorModel
+		diags.Append(plan.GeneratePassword.As(ctx, &generator, basetypes.ObjectAsOptions{})...)
+		if diags.HasError() {
+			return diags
+		}
+
+		if err := r.passwordPolicy.checkGenerator(generator); err != nil {
+			diags.AddAttributeError(path.Root("generate_password"), "Password Policy Violation", err.Error())
+		}
+	}
+
+	return diags
+}
+
+// enforcePasswordPolicy checks the password about to be sent to MongoDB,
+// including write-only and generated passwords. Pre-hashed passwords cannot
+// be checked.
+func (r *userResource) enforcePasswordPolicy(ctx context.Context, config tfsdk.Config, plan userResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if r.passwordPolicy == nil || !plan.PasswordHash.IsNull() {
+		return diags
+	}
+
+	password, diags := configuredPassword(ctx, config, plan)
+	if diags.HasError() {
+		return diags
+	}
+
+	if err := r.passwordPolicy.check(password, plan.User.ValueString(), plan.Db.ValueString()); err != nil {
+		// Never echo the password, redact it in case it leaks into err.
+		diags.AddError(
+			"Password Policy Violation",
+			"Could not set the password of user <"+plan.User.ValueString()+">, "+redactPassword(err.Error(), password),
+		)
+	}
+
+	return diags
+}
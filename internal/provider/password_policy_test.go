@@ -0,0 +1,191 @@
+package provider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestPasswordPolicyCheck(t *testing.T) {
+	policy := &passwordPolicy{
+		minLength:           12,
+		requireLower:        true,
+		requireUpper:        true,
+		requireNumeric:      true,
+		requireSpecial:      true,
+		forbiddenSubstrings: []string{"acme"},
+		denylist:            map[string]bool{"correcthorse1!a": true},
+	}
+
+	testCases := map[string]bool{
+		"Str0ng!Passw0rd":  true,
+		"Sh0rt!a":          false,
+		"alllowercase1!xx": false,
+		"ALLUPPERCASE1!XX": false,
+		"NoDigitsHere!!xx": false,
+		"NoSpecial123abcD": false,
+		"Acme!Passw0rd123": false,
+		"Str0ng!AppUser12": false,
+		"Str0ng!Orders123": false,
+		"CorrectHorse1!A":  false,
+	}
+
+	for password, expected := range testCases {
+		t.Run(password, func(t *testing.T) {
+			err := policy.check(password, "appuser", "orders")
+			if expected && err != nil {
+				t.Errorf("expected valid, got %s", err)
+			}
+			if !expected && err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	var unset *passwordPolicy
+	if err := unset.check("a", "appuser", "orders"); err != nil {
+		t.Errorf("expected no error without a policy, got %s", err)
+	}
+}
+
+func TestPasswordPolicyCheckGenerator(t *testing.T) {
+	policy := &passwordPolicy{minLength: 24, requireSpecial: true}
+
+	testCases := map[string]struct {
+		generator passwordGeneratorModel
+		expected  bool
+	}{
+		"defaults": {
+			generator: passwordGeneratorModel{},
+			expected:  true,
+		},
+		"too short": {
+			generator: passwordGeneratorModel{Length: types.Int64Value(16)},
+			expected:  false,
+		},
+		"special disabled": {
+			generator: passwordGeneratorModel{Special: types.BoolValue(false)},
+			expected:  false,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := policy.checkGenerator(testCase.generator)
+			if testCase.expected && err != nil {
+				t.Errorf("expected valid, got %s", err)
+			}
+			if !testCase.expected && err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestGeneratePasswordMeetsPolicy(t *testing.T) {
+	ctx := context.Background()
+	r := &userResource{passwordPolicy: &passwordPolicy{}}
+
+	generator := func(excludeCharacters string) types.Object {
+		return types.ObjectValueMust(map[string]attr.Type{
+			"length":             types.Int64Type,
+			"lower":              types.BoolType,
+			"upper":              types.BoolType,
+			"numeric":            types.BoolType,
+			"special":            types.BoolType,
+			"exclude_characters": types.StringType,
+		}, map[string]attr.Value{
+			"length":             types.Int64Value(8),
+			"lower":              types.BoolValue(true),
+			"upper":              types.BoolValue(false),
+			"numeric":            types.BoolValue(false),
+			"special":            types.BoolValue(false),
+			"exclude_characters": types.StringValue(excludeCharacters),
+		})
+	}
+
+	// Most lowercase passwords of 8 characters contain the username "a"
+	// within a few attempts.
+	for i := 0; i < 50; i++ {
+		plan := userResourceModel{
+			User:              types.StringValue("a"),
+			Db:                types.StringValue("test"),
+			GeneratePassword:  generator(""),
+			GeneratedPassword: types.StringUnknown(),
+		}
+
+		diags := r.generatePassword(ctx, &plan)
+		if diags.HasError() {
+			t.Fatalf("unexpected diagnostics: %v", diags)
+		}
+
+		if strings.Contains(plan.GeneratedPassword.ValueString(), "a") {
+			t.Fatalf("expected the password to not contain the username, got %s", plan.GeneratedPassword.ValueString())
+		}
+	}
+
+	plan := userResourceModel{
+		User:              types.StringValue("a"),
+		Db:                types.StringValue("test"),
+		GeneratePassword:  generator(strings.ReplaceAll(lowerCharacters, "a", "")),
+		GeneratedPassword: types.StringUnknown(),
+	}
+
+	diags := r.generatePassword(ctx, &plan)
+	if !diags.HasError() {
+		t.Errorf("expected an error for passwords that can never meet the policy, got %s", plan.GeneratedPassword.ValueString())
+	}
+}
+
+func TestNewPasswordPolicy(t *testing.T) {
+	denylist := filepath.Join(t.TempDir(), "denylist.txt")
+	err := os.WriteFile(denylist, []byte("# common passwords\nPassword123!\n\n  Welcome1!  \n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	attributeTypes := map[string]attr.Type{
+		"min_length":           types.Int64Type,
+		"require_lower":        types.BoolType,
+		"require_upper":        types.BoolType,
+		"require_numeric":      types.BoolType,
+		"require_special":      types.BoolType,
+		"forbidden_substrings": types.ListType{ElemType: types.StringType},
+		"denylist_file":        types.StringType,
+	}
+	policyValue := func(file string) types.Object {
+		return types.ObjectValueMust(attributeTypes, map[string]attr.Value{
+			"min_length":           types.Int64Null(),
+			"require_lower":        types.BoolNull(),
+			"require_upper":        types.BoolNull(),
+			"require_numeric":      types.BoolNull(),
+			"require_special":      types.BoolNull(),
+			"forbidden_substrings": types.ListValueMust(types.StringType, []attr.Value{types.StringValue("Acme")}),
+			"denylist_file":        types.StringValue(file),
+		})
+	}
+
+	policy, diags := newPasswordPolicy(context.Background(), policyValue(denylist))
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	for _, password := range []string{"password123!", "WELCOME1!", "myACMEpassword"} {
+		if policy.check(password, "", "") == nil {
+			t.Errorf("expected %q to be rejected", password)
+		}
+	}
+	if err := policy.check("# common passwords", "", ""); err != nil {
+		t.Errorf("expected comments to be ignored, got %s", err)
+	}
+
+	_, diags = newPasswordPolicy(context.Background(), policyValue(filepath.Join(t.TempDir(), "missing.txt")))
+	if !diags.HasError() {
+		t.Error("expected an error for a missing denylist file")
+	}
+}
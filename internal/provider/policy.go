@@ -0,0 +1,214 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+type providerPolicyModel struct {
+	ForbiddenRoles                    types.Set  `tfsdk:"forbidden_roles"`
+	AllowedDatabases                  types.List `tfsdk:"allowed_databases"`
+	AllowedRoleDatabases              types.List `tfsdk:"allowed_role_databases"`
+	RequireAuthenticationRestrictions types.Bool `tfsdk:"require_authentication_restrictions"`
+}
+
+// userPolicy restricts the users the provider manages, it is checked when
+// planning every user.
+type userPolicy struct {
+	// forbiddenRoles are "<role>" or "<role>@<db>" globs.
+	forbiddenRoles []string
+	// allowedDatabases and allowedRoleDatabases are globs, nil allows any
+	// database.
+	allowedDatabases                  []string
+	allowedRoleDatabases              []string
+	requireAuthenticationRestrictions bool
+}
+
+// policyBlock returns the schema of the provider policy block.
+func policyBlock() schema.SingleNestedBlock {
+	return schema.SingleNestedBlock{
+		Description: "Guardrails checked when planning every user, violations fail the plan",
+		Attributes: map[string]schema.Attribute{
+			"forbidden_roles": schema.SetAttribute{
+				Description: "Roles that cannot be granted, as \"<role>\" for any database or \"<role>@<db>\". Both parts may be globs such as \"*AnyDatabase\"",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"allowed_databases": schema.ListAttribute{
+				Description: "Globs of the databases users can be created in, any database when unset",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"allowed_role_databases": schema.ListAttribute{
+				Description: "Globs of the databases roles can be granted on, any database when unset",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"require_authentication_restrictions": schema.BoolAttribute{
+				Description: "Require every user to have authentication_restrictions",
+				Optional:    true,
+			},
+		},
+	}
+}
+
+// newUserPolicy converts the policy block, returning nil when it is not set.
+func newUserPolicy(ctx context.Context, policy types.Object) (*userPolicy, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if policy.IsNull() {
+		return nil, diags
+	}
+
+	if policy.IsUnknown() {
+		diags.AddAttributeError(
+			path.Root("policy"),
+			"Unknown Policy",
+			"The provider cannot enforce a policy with unknown values, set the policy block statically in the configuration.",
+		)
+		return nil, diags
+	}
+
+	var model providerPolicyModel
+	diags.Append(policy.As(ctx, &model, basetypes.ObjectAsOptions{})...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	result := &userPolicy{
+		requireAuthenticationRestrictions: model.RequireAuthenticationRestrictions.ValueBool(),
+	}
+
+	var d diag.Diagnostics
+	result.forbiddenRoles, d = policyGlobs(ctx, "forbidden_roles", model.ForbiddenRoles)
+	diags.Append(d...)
+	result.allowedDatabases, d = policyGlobs(ctx, "allowed_databases", model.AllowedDatabases)
+	diags.Append(d...)
+	result.allowedRoleDatabases, d = policyGlobs(ctx, "allowed_role_databases", model.AllowedRoleDatabases)
+	diags.Append(d...)
+
+	return result, diags
+}
+
+// stringCollection is a set or list of strings.
+type stringCollection interface {
+	IsNull() bool
+	ElementsAs(ctx context.Context, target interface{}, allowUnhandled bool) diag.Diagnostics
+}
+
+// policyGlobs returns the globs of a policy attribute, nil when it is unset
+// and an empty slice, which matches nothing, when it is empty.
+func policyGlobs(ctx context.Context, name string, globs stringCollection) ([]string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if globs.IsNull() {
+		return nil, diags
+	}
+
+	values := []string{}
+	diags.Append(globs.ElementsAs(ctx, &values, false)...)
+
+	for _, value := range values {
+		for _, pattern := range strings.SplitN(value, "@", 2) {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				diags.AddAttributeError(
+					path.Root("policy").AtName(name),
+					"Invalid Policy Glob",
+					fmt.Sprintf("The pattern %q is not a valid glob: %s", value, err),
+				)
+			}
+		}
+	}
+
+	return values, diags
+}
+
+// matchesAny reports whether value matches any of the globs.
+func matchesAny(globs []string, value string) bool {
+	for _, glob := range globs {
+		if matched, _ := filepath.Match(glob, value); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// forbidsRole reports whether the policy forbids granting role on db.
+func (p *userPolicy) forbidsRole(role string, db string) bool {
+	for _, forbidden := range p.forbiddenRoles {
+		rolePattern, dbPattern, scoped := strings.Cut(forbidden, "@")
+		if !scoped {
+			dbPattern = "*"
+		}
+
+		roleMatched, _ := filepath.Match(rolePattern, role)
+		dbMatched, _ := filepath.Match(dbPattern, db)
+		if roleMatched && dbMatched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// check returns a diagnostic for every violation of the policy by a user in
+// db with roles. Unknown values are checked once they are known.
+func (p *userPolicy) check(db types.String, roles types.Set, restricted bool) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if p == nil {
+		return diags
+	}
+
+	if p.allowedDatabases != nil && !db.IsUnknown() && !matchesAny(p.allowedDatabases, db.ValueString()) {
+		diags.AddAttributeError(
+			path.Root("db"),
+			"Policy Violation",
+			fmt.Sprintf("The provider policy does not allow users in the %q database, allowed databases are %q.", db.ValueString(), p.allowedDatabases),
+		)
+	}
+
+	if p.requireAuthenticationRestrictions && !restricted {
+		diags.AddAttributeError(
+			path.Root("authentication_restrictions"),
+			"Policy Violation",
+			"The provider policy requires every user to have authentication_restrictions.",
+		)
+	}
+
+	if roles.IsUnknown() {
+		return diags
+	}
+
+	for _, element := range roles.Elements() {
+		role, ok := roleFromValue(element)
+		if !ok || role.Role.IsUnknown() || role.Db.IsUnknown() {
+			continue
+		}
+
+		name, roleDb := role.Role.ValueString(), role.Db.ValueString()
+		if p.forbidsRole(name, roleDb) {
+			diags.AddAttributeError(
+				path.Root("roles"),
+				"Policy Violation",
+				fmt.Sprintf("The provider policy forbids granting the role %s@%s.", name, roleDb),
+			)
+		}
+
+		if p.allowedRoleDatabases != nil && !matchesAny(p.allowedRoleDatabases, roleDb) {
+			diags.AddAttributeError(
+				path.Root("roles"),
+				"Policy Violation",
+				fmt.Sprintf("The provider policy does not allow roles on the %q database, allowed databases are %q.", roleDb, p.allowedRoleDatabases),
+			)
+		}
+	}
+
+	return diags
+}
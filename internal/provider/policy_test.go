@@ -0,0 +1,161 @@
+package provider
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestUserPolicyCheck(t *testing.T) {
+	policy := &userPolicy{
+		forbiddenRoles:                    []string{"root", "*AnyDatabase", "dbOwner@admin"},
+		allowedDatabases:                  []string{"tenant_*"},
+		allowedRoleDatabases:              []string{"tenant_*", "shared"},
+		requireAuthenticationRestrictions: true,
+	}
+
+	role := func(db string, name string) attr.Value {
+		return types.ObjectValueMust(roleObjectType.AttrTypes, map[string]attr.Value{
+			"db":   types.StringValue(db),
+			"role": types.StringValue(name),
+		})
+	}
+
+	testCases := map[string]struct {
+		db         types.String
+		roles      []attr.Value
+		restricted bool
+		errors     int
+	}{
+		"allowed": {
+			db:         types.StringValue("tenant_a"),
+			roles:      []attr.Value{role("tenant_a", "readWrite"), role("shared", "read")},
+			restricted: true,
+		},
+		"forbidden database": {
+			db:         types.StringValue("admin"),
+			roles:      []attr.Value{},
+			restricted: true,
+			errors:     1,
+		},
+		"unknown database": {
+			db:         types.StringUnknown(),
+			roles:      []attr.Value{},
+			restricted: true,
+		},
+		"forbidden roles": {
+			db:         types.StringValue("tenant_a"),
+			roles:      []attr.Value{role("admin", "root"), role("tenant_a", "readAnyDatabase")},
+			restricted: true,
+			// root is also on a database that is not allowed.
+			errors: 3,
+		},
+		"scoped forbidden role": {
+			db:         types.StringValue("tenant_a"),
+			roles:      []attr.Value{role("tenant_a", "dbOwner")},
+			restricted: true,
+		},
+		"role database not allowed": {
+			db:         types.StringValue("tenant_a"),
+			roles:      []attr.Value{role("tenant_b_other", "read"), role("other", "read")},
+			restricted: true,
+			errors:     1,
+		},
+		"missing restrictions": {
+			db:     types.StringValue("tenant_a"),
+			roles:  []attr.Value{},
+			errors: 1,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			roles := types.SetValueMust(roleObjectType, testCase.roles)
+			diags := policy.check(testCase.db, roles, testCase.restricted)
+			if errors := diags.ErrorsCount(); errors != testCase.errors {
+				t.Errorf("expected %d errors, got %d: %v", testCase.errors, errors, diags)
+			}
+		})
+	}
+
+	var unset *userPolicy
+	if diags := unset.check(types.StringValue("admin"), types.SetNull(roleObjectType), false); diags.HasError() {
+		t.Errorf("expected no errors without a policy, got %v", diags)
+	}
+}
+
+func TestNewUserPolicy(t *testing.T) {
+	ctx := context.Background()
+	attributeTypes := map[string]attr.Type{
+		"forbidden_roles":                     types.SetType{ElemType: types.StringType},
+		"allowed_databases":                   types.ListType{ElemType: types.StringType},
+		"allowed_role_databases":              types.ListType{ElemType: types.StringType},
+		"require_authentication_restrictions": types.BoolType,
+	}
+
+	policy, diags := newUserPolicy(ctx, types.ObjectValueMust(attributeTypes, map[string]attr.Value{
+		"forbidden_roles":                     types.SetValueMust(types.StringType, []attr.Value{types.StringValue("root")}),
+		"allowed_databases":                   types.ListValueMust(types.StringType, []attr.Value{}),
+		"allowed_role_databases":              types.ListNull(types.StringType),
+		"require_authentication_restrictions": types.BoolNull(),
+	}))
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	if policy.allowedDatabases == nil || len(policy.allowedDatabases) != 0 {
+		t.Errorf("expected an empty allowed_databases to allow nothing, got %#v", policy.allowedDatabases)
+	}
+	if policy.allowedRoleDatabases != nil {
+		t.Errorf("expected an unset allowed_role_databases to allow anything, got %#v", policy.allowedRoleDatabases)
+	}
+
+	_, diags = newUserPolicy(ctx, types.ObjectValueMust(attributeTypes, map[string]attr.Value{
+		"forbidden_roles":                     types.SetValueMust(types.StringType, []attr.Value{types.StringValue("[root")}),
+		"allowed_databases":                   types.ListNull(types.StringType),
+		"allowed_role_databases":              types.ListNull(types.StringType),
+		"require_authentication_restrictions": types.BoolNull(),
+	}))
+	if !diags.HasError() {
+		t.Error("expected an error for an invalid glob")
+	}
+}
+
+func TestAccUserResourcePolicy(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "mongodb-users" {
+  host = "localhost:27017"
+  username = "root"
+  password = "password123"
+
+  policy {
+    forbidden_roles = ["root"]
+    allowed_databases = ["test"]
+  }
+}
+
+resource "mongodb-users_user" "test_policy" {
+  user = "test_policy"
+  db = "test"
+  password = "abc123"
+  roles = [
+    {
+      db = "admin"
+      role = "root"
+    }
+  ]
+}
+`,
+				ExpectError: regexp.MustCompile("Policy Violation"),
+			},
+		},
+	})
+}
@@ -0,0 +1,209 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// escalationRoles are the built-in roles that let a user grant itself any
+// privilege, granting them is called out in the privilege summary.
+var escalationRoles = []string{"root", "userAdminAnyDatabase", "dbOwner", "__system"}
+
+// privilegeSet holds the actions allowed on each namespace, keyed by the
+// namespace as formatted by privilegeNamespace.
+type privilegeSet map[string]map[string]bool
+
+type privilegesInfoResponse struct {
+	commandResponse `bson:",inline"`
+	Roles           []struct {
+		InheritedPrivileges []dbPrivilege `bson:"inheritedPrivileges"`
+	} `bson:"roles"`
+}
+
+type dbPrivilege struct {
+	Resource bson.M   `bson:"resource"`
+	Actions  []string `bson:"actions"`
+}
+
+// rolePrivileges returns the privileges granted by roles, including the
+// privileges of the roles they inherit from.
+func rolePrivileges(ctx context.Context, client *mongo.Client, roles []userRoleModel) (privilegeSet, error) {
+	privileges := privilegeSet{}
+	if len(roles) == 0 {
+		return privileges, nil
+	}
+
+	var response privilegesInfoResponse
+	cmd := bson.D{
+		{Key: "rolesInfo", Value: rolesToBson(roles)},
+		{Key: "showPrivileges", Value: true},
+		{Key: "showBuiltinRoles", Value: true},
+	}
+
+	err := client.Database("admin").RunCommand(ctx, cmd).Decode(&response)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, role := range response.Roles {
+		for _, privilege := range role.InheritedPrivileges {
+			namespace := privilegeNamespace(privilege.Resource)
+			if privileges[namespace] == nil {
+				privileges[namespace] = map[string]bool{}
+			}
+			for _, action := range privilege.Actions {
+				privileges[namespace][action] = true
+			}
+		}
+	}
+
+	return privileges, nil
+}
+
+// privilegeNamespace formats the resource of a privilege, "*" standing for
+// any database or any collection.
+func privilegeNamespace(resource bson.M) string {
+	if cluster, _ := resource["cluster"].(bool); cluster {
+		return "cluster"
+	}
+	if anyResource, _ := resource["anyResource"].(bool); anyResource {
+		return "any resource"
+	}
+
+	db, _ := resource["db"].(string)
+	collection, _ := resource["collection"].(string)
+	if db == "" {
+		db = "*"
+	}
+	if collection == "" {
+		collection = "*"
+	}
+
+	return db + "." + collection
+}
+
+// privilegeDelta returns, per namespace, the sorted actions in next but not
+// in previous.
+func privilegeDelta(previous privilegeSet, next privilegeSet) map[string][]string {
+	delta := map[string][]string{}
+	for namespace, actions := range next {
+		for action := range actions {
+			if !previous[namespace][action] {
+				delta[namespace] = append(delta[namespace], action)
+			}
+		}
+		sort.Strings(delta[namespace])
+	}
+
+	return delta
+}
+
+// formatPrivilegeDelta formats the gained and lost actions one namespace per
+// line, prefixed with + and - like a diff.
+func formatPrivilegeDelta(gained map[string][]string, lost map[string][]string) string {
+	var lines []string
+	for _, change := range []struct {
+		prefix string
+		delta  map[string][]string
+	}{
+		{"+", gained},
+		{"-", lost},
+	} {
+		namespaces := make([]string, 0, len(change.delta))
+		for namespace, actions := range change.delta {
+			if len(actions) > 0 {
+				namespaces = append(namespaces, namespace)
+			}
+		}
+		sort.Strings(namespaces)
+
+		for _, namespace := range namespaces {
+			lines = append(lines, fmt.Sprintf("  %s %s: %s", change.prefix, namespace, strings.Join(change.delta[namespace], ", ")))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// grantedEscalations returns the escalation roles granted by grant.
+func grantedEscalations(grant []userRoleModel) []string {
+	var escalations []string
+	for _, role := range grant {
+		for _, escalation := range escalationRoles {
+			if role.Role.ValueString() == escalation {
+				escalations = append(escalations, role.Role.ValueString()+"@"+role.Db.ValueString())
+			}
+		}
+	}
+	sort.Strings(escalations)
+
+	return escalations
+}
+
+// summarizeRoleChanges warns with the effective actions gained and lost when
+// the roles of a user change, so reviewers see more than role names.
+func (r *userResource) summarizeRoleChanges(ctx context.Context, state userResourceModel, plan userResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if r.client == nil || plan.Roles.IsNull() || plan.Roles.IsUnknown() || plan.Roles.Equal(state.Roles) {
+		return diags
+	}
+
+	stateRoles, d := rolesFromSet(ctx, state.Roles)
+	diags.Append(d...)
+	planRoles, d := rolesFromSet(ctx, plan.Roles)
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+
+	// Roles coming from other resources cannot be expanded yet.
+	for _, role := range planRoles {
+		if role.Db.IsUnknown() || role.Role.IsUnknown() {
+			return diags
+		}
+	}
+
+	previous, err := rolePrivileges(ctx, r.client, stateRoles)
+	if err != nil {
+		diags.AddAttributeWarning(
+			path.Root("roles"),
+			"Could Not Summarize Privilege Changes",
+			"Could not expand the roles of user <"+plan.User.ValueString()+"> with rolesInfo, unexpected error: "+err.Error(),
+		)
+		return diags
+	}
+
+	next, err := rolePrivileges(ctx, r.client, planRoles)
+	if err != nil {
+		diags.AddAttributeWarning(
+			path.Root("roles"),
+			"Could Not Summarize Privilege Changes",
+			"Could not expand the roles of user <"+plan.User.ValueString()+"> with rolesInfo, unexpected error: "+err.Error(),
+		)
+		return diags
+	}
+
+	summary := formatPrivilegeDelta(privilegeDelta(previous, next), privilegeDelta(next, previous))
+	if summary == "" {
+		summary = "  no effective change"
+	}
+
+	detail := "The role changes of user <" + plan.User.ValueString() + "> change its effective privileges:\n" + summary
+
+	grant, _ := diffRoles(stateRoles, planRoles)
+	if escalations := grantedEscalations(grant); len(escalations) > 0 {
+		detail = "Privilege escalation: the user is granted " + strings.Join(escalations, ", ") +
+			", which can grant any privilege.\n\n" + detail
+	}
+
+	diags.AddAttributeWarning(path.Root("roles"), "Privilege Changes", detail)
+
+	return diags
+}
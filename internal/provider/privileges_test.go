@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestPrivilegeNamespace(t *testing.T) {
+	testCases := map[string]struct {
+		resource bson.M
+		expected string
+	}{
+		"cluster":      {bson.M{"cluster": true}, "cluster"},
+		"any resource": {bson.M{"anyResource": true}, "any resource"},
+		"database":     {bson.M{"db": "app", "collection": ""}, "app.*"},
+		"collection":   {bson.M{"db": "app", "collection": "orders"}, "app.orders"},
+		"any database": {bson.M{"db": "", "collection": ""}, "*.*"},
+		"system":       {bson.M{"db": "", "collection": "system.js"}, "*.system.js"},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if actual := privilegeNamespace(testCase.resource); actual != testCase.expected {
+				t.Errorf("expected %q, got %q", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestFormatPrivilegeDelta(t *testing.T) {
+	previous := privilegeSet{
+		"app.*":   {"find": true, "insert": true},
+		"cluster": {"serverStatus": true},
+	}
+	next := privilegeSet{
+		"app.*": {"find": true, "insert": true, "update": true, "remove": true},
+		"*.*":   {"find": true},
+	}
+
+	gained := privilegeDelta(previous, next)
+	lost := privilegeDelta(next, previous)
+
+	expected := "  + *.*: find\n  + app.*: remove, update\n  - cluster: serverStatus"
+	if actual := formatPrivilegeDelta(gained, lost); actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+
+	if actual := formatPrivilegeDelta(privilegeDelta(next, next), privilegeDelta(next, next)); actual != "" {
+		t.Errorf("expected no changes, got:\n%s", actual)
+	}
+}
+
+func TestGrantedEscalations(t *testing.T) {
+	grant := []userRoleModel{
+		{Db: types.StringValue("app"), Role: types.StringValue("readWrite")},
+		{Db: types.StringValue("app"), Role: types.StringValue("dbOwner")},
+		{Db: types.StringValue("admin"), Role: types.StringValue("root")},
+	}
+
+	expected := []string{"dbOwner@app", "root@admin"}
+	if actual := grantedEscalations(grant); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
@@ -27,10 +27,39 @@ type mongodbUsersProvider struct {
 	version string
 }
 
+// mongodbUsersProviderData is handed to resources during Configure.
+type mongodbUsersProviderData struct {
+	client *mongo.Client
+	// host is kept so resources can open connections as other users.
+	host string
+	// x509MemberSubject is the subject of the cluster member certificates,
+	// empty when not configured.
+	x509MemberSubject string
+	// roles caches role lookups for the duration of a plan or apply.
+	roles *roleCache
+	// policy is nil when no policy block is configured.
+	policy *userPolicy
+	// passwordPolicy is nil when no password_policy block is configured.
+	passwordPolicy *passwordPolicy
+	// owner and version are stamped on created users, users stamped with
+	// another owner are not changed.
+	owner   string
+	version string
+	// adoptExistingUsers is the default of adopt_existing.
+	adoptExistingUsers bool
+}
+
 type mongodbUsersProviderModel struct {
 	Host     types.String `tfsdk:"host"`
 	Username types.String `tfsdk:"username"`
 	Password types.String `tfsdk:"password"`
+
+	X509MemberSubject types.String `tfsdk:"x509_member_subject"`
+	Policy            types.Object `tfsdk:"policy"`
+	PasswordPolicy    types.Object `tfsdk:"password_policy"`
+	Owner             types.String `tfsdk:"owner"`
+
+	AdoptExistingUsers types.Bool `tfsdk:"adopt_existing_users"`
 }
 
 func (p *mongodbUsersProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -54,6 +83,25 @@ func (p *mongodbUsersProvider) Schema(ctx context.Context, req provider.SchemaRe
 				Required:    true,
 				Sensitive:   true,
 			},
+			"adopt_existing_users": schema.BoolAttribute{
+				Description: "Default of the adopt_existing attribute of users, adopt existing users instead of failing to create them",
+				Optional:    true,
+			},
+			"owner": schema.StringAttribute{
+				Description: "Identifier of the workspace or stack managing the users, stamped on created users and on unmarked users when they are updated. " +
+					"Users stamped with another owner are neither updated nor deleted. Without an owner users are stamped with an empty owner, shared by every configuration without one, and a warning is shown. " +
+					"May also be provided with MONGODB_OWNER environment variable",
+				Optional: true,
+			},
+			"x509_member_subject": schema.StringAttribute{
+				Description: "RFC 2253 subject of the certificates cluster members authenticate with, X.509 users that MongoDB would take for a cluster member are rejected during plan. " +
+					"May also be provided with MONGODB_X509_MEMBER_SUBJECT environment variable",
+				Optional: true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"policy":          policyBlock(),
+			"password_policy": passwordPolicyBlock(),
 		},
 	}
 }
@@ -104,6 +152,8 @@ func (p *mongodbUsersProvider) Configure(ctx context.Context, req provider.Confi
 	host := os.Getenv("MONGODB_HOST")
 	username := os.Getenv("MONGODB_USERNAME")
 	password := os.Getenv("MONGODB_PASSWORD")
+	x509MemberSubject := os.Getenv("MONGODB_X509_MEMBER_SUBJECT")
+	owner := os.Getenv("MONGODB_OWNER")
 
 	if !config.Host.IsNull() {
 		host = config.Host.ValueString()
@@ -117,6 +167,14 @@ func (p *mongodbUsersProvider) Configure(ctx context.Context, req provider.Confi
 		password = config.Password.ValueString()
 	}
 
+	if !config.X509MemberSubject.IsNull() {
+		x509MemberSubject = config.X509MemberSubject.ValueString()
+	}
+
+	if !config.Owner.IsNull() {
+		owner = config.Owner.ValueString()
+	}
+
 	// If any of the expected configurations are missing, return
 	// errors with provider-specific guidance.
 
@@ -150,6 +208,21 @@ func (p *mongodbUsersProvider) Configure(ctx context.Context, req provider.Confi
 		)
 	}
 
+	if x509MemberSubject != "" {
+		if _, err := parseDistinguishedName(x509MemberSubject); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("x509_member_subject"),
+				"Invalid X.509 Member Subject",
+				"The x509_member_subject must be an RFC 2253 distinguished name: "+err.Error(),
+			)
+		}
+	}
+
+	policy, diags := newUserPolicy(ctx, config.Policy)
+	resp.Diagnostics.Append(diags...)
+	passwordPolicy, diags := newPasswordPolicy(ctx, config.PasswordPolicy)
+	resp.Diagnostics.Append(diags...)
+
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -175,13 +248,26 @@ func (p *mongodbUsersProvider) Configure(ctx context.Context, req provider.Confi
 
 	// Make the MongoDb client available during DataSource and Resource
 	// type Configure methods.
-	resp.DataSourceData = client
-	resp.ResourceData = client
+	providerData := &mongodbUsersProviderData{
+		client:            client,
+		host:              host,
+		x509MemberSubject: x509MemberSubject,
+		roles:             newRoleCache(),
+		policy:            policy,
+		passwordPolicy:    passwordPolicy,
+		owner:             owner,
+		version:           p.version,
+
+		adoptExistingUsers: config.AdoptExistingUsers.ValueBool(),
+	}
+	resp.DataSourceData = providerData
+	resp.ResourceData = providerData
 }
 
 func (p *mongodbUsersProvider) Resources(_ context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewUserResource,
+		NewRotatingUserResource,
 	}
 }
 
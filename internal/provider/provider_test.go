@@ -4,8 +4,15 @@
 package provider
 
 import (
+	"context"
+	"testing"
+	"time"
+
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
-	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 const (
@@ -18,12 +25,36 @@ provider "mongodb-users" {
 `
 )
 
+// testAccMongoCommand runs command against db with the credentials from
+// providerConfig, for tests that change users outside of Terraform.
+func testAccMongoCommand(t *testing.T, db string, command bson.D) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017").
+		SetAuth(options.Credential{Username: "root", Password: "password123"}))
+	if err != nil {
+		t.Fatalf("could not connect to MongoDB: %s", err)
+	}
+
+	defer func() {
+		_ = client.Disconnect(ctx)
+	}()
+
+	err = client.Database(db).RunCommand(ctx, command).Err()
+	if err != nil {
+		t.Fatalf("could not run command: %s", err)
+	}
+}
+
 // testAccProtoV6ProviderFactories are used to instantiate a provider during
 // acceptance testing. The factory function will be invoked for every Terraform
 // CLI command executed to create a provider server to which the CLI can
 // reattach.
-var testAccProtoV5ProviderFactories = map[string]func() (tfprotov5.ProviderServer, error){
-	"mongodb-users": providerserver.NewProtocol5WithError(New("test")()),
+var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
+	"mongodb-users": providerserver.NewProtocol6WithError(New("test")()),
 }
 
 //func testAccPreCheck(t *testing.T) {
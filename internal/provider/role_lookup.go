@@ -0,0 +1,134 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// roleCache caches the custom roles of each database. The provider is
+// configured once per plan or apply, so lookups are shared by every user of
+// that run.
+type roleCache struct {
+	mu    sync.Mutex
+	roles map[string]*roleCacheEntry
+}
+
+// roleCacheEntry holds the result of looking up the roles of a database,
+// including the failure of the lookup, so every database is queried once.
+type roleCacheEntry struct {
+	once  sync.Once
+	roles map[string]bool
+	err   error
+}
+
+type rolesInfoResponse struct {
+	commandResponse `bson:",inline"`
+	Roles           []dbRoleInfo `bson:"roles"`
+}
+
+type dbRoleInfo struct {
+	Role string `bson:"role"`
+	Db   string `bson:"db"`
+}
+
+func newRoleCache() *roleCache {
+	return &roleCache{roles: map[string]*roleCacheEntry{}}
+}
+
+// exists reports whether the custom role exists in db. The roles of db are
+// queried by the first lookup only, later lookups of any role of db,
+// including missing ones, are answered from the cache. Lookups of other
+// databases are not held up by the query.
+func (c *roleCache) exists(ctx context.Context, client *mongo.Client, db string, role string) (bool, error) {
+	c.mu.Lock()
+	entry, ok := c.roles[db]
+	if !ok {
+		entry = &roleCacheEntry{}
+		c.roles[db] = entry
+	}
+	c.mu.Unlock()
+
+	entry.once.Do(func() {
+		entry.roles, entry.err = customRoles(ctx, client, db)
+	})
+	if entry.err != nil {
+		return false, entry.err
+	}
+
+	return entry.roles[role], nil
+}
+
+// customRoles returns the names of the custom roles defined in db.
+func customRoles(ctx context.Context, client *mongo.Client, db string) (map[string]bool, error) {
+	var response rolesInfoResponse
+	err := client.Database(db).RunCommand(ctx, bson.D{{Key: "rolesInfo", Value: 1}}).Decode(&response)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := map[string]bool{}
+	for _, role := range response.Roles {
+		roles[role.Role] = true
+	}
+
+	return roles, nil
+}
+
+// checkCustomRoles warns when a custom role in roles does not exist, which
+// would fail createUser or grantRolesToUser during apply. It is not an
+// error as another resource of the plan may create the role under a literal
+// name. Roles with unknown names or databases are produced by other
+// resources of the plan and are not checked.
+func (r *userResource) checkCustomRoles(ctx context.Context, plan userResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if r.client == nil || r.roles == nil || plan.Roles.IsUnknown() {
+		return diags
+	}
+
+	var missing []string
+	for _, element := range plan.Roles.Elements() {
+		role, ok := roleFromValue(element)
+		if !ok || role.Db.IsUnknown() || role.Role.IsUnknown() {
+			continue
+		}
+
+		if _, builtin := builtinRoles.validDatabases(role.Role.ValueString()); builtin {
+			continue
+		}
+
+		exists, err := r.roles.exists(ctx, r.client, role.Db.ValueString(), role.Role.ValueString())
+		if err != nil {
+			diags.AddAttributeWarning(
+				path.Root("roles"),
+				"Could Not Verify Roles",
+				"Could not look up the roles of database <"+role.Db.ValueString()+">, unexpected error: "+err.Error(),
+			)
+			return diags
+		}
+
+		if !exists {
+			missing = append(missing, fmt.Sprintf("%s@%s", role.Role.ValueString(), role.Db.ValueString()))
+		}
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		diags.AddAttributeWarning(
+			path.Root("roles"),
+			"Role Not Found",
+			"The roles "+strings.Join(missing, ", ")+" of user <"+plan.User.ValueString()+"> are neither built-in nor defined in their database, "+
+				"granting them fails unless they are created before the user. "+
+				"If another resource creates them, reference its role name from roles so the user waits for it.",
+		)
+	}
+
+	return diags
+}
@@ -0,0 +1,136 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// roleObjectType is the element type of roles attributes.
+var roleObjectType = types.ObjectType{
+	AttrTypes: map[string]attr.Type{
+		"db":   types.StringType,
+		"role": types.StringType,
+	},
+}
+
+// rolesAttribute returns the schema of a roles attribute, db defaults to
+// the database of the user like bare role names do in MongoDB.
+func rolesAttribute(description string, required bool) schema.SetNestedAttribute {
+	return schema.SetNestedAttribute{
+		Description: description,
+		Required:    required,
+		Optional:    !required,
+		Validators: []validator.Set{
+			builtinRolesValidator{},
+		},
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"db": schema.StringAttribute{
+					Description: "Database of the role, defaults to the database of the user and required for users in the \"" + externalDb + "\" database",
+					Optional:    true,
+					Computed:    true,
+					Validators: []validator.String{
+						databaseNameValidator{roles: true},
+					},
+					PlanModifiers: []planmodifier.String{
+						roleDbModifier{},
+					},
+				},
+				"role": schema.StringAttribute{
+					Description: "Name of the role",
+					Required:    true,
+					Validators: []validator.String{
+						roleNameValidator{},
+					},
+				},
+			},
+		},
+	}
+}
+
+// roleDbModifier plans an omitted role db as the db of the user, so state
+// read back from MongoDB matches the plan. Users in $external have to name
+// the db of their roles.
+type roleDbModifier struct{}
+
+func (m roleDbModifier) Description(_ context.Context) string {
+	return "Defaults the role database to the database of the user."
+}
+
+func (m roleDbModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m roleDbModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if !req.ConfigValue.IsNull() {
+		return
+	}
+
+	var db types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("db"), &db)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Roles cannot be defined in $external, the database has to be explicit.
+	if db.ValueString() == externalDb {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Role Database Required",
+			"Roles of users in the \""+externalDb+"\" database need an explicit db, roles cannot be defined in \""+externalDb+"\".",
+		)
+		return
+	}
+
+	resp.PlanValue = db
+}
+
+// rolesFromSet converts a roles set to the roles it contains. Null and
+// unknown sets convert to nil, an empty set to an empty slice.
+//
+// The set is only fully known once Terraform applies, roles may come from
+// other resources and be unknown while planning.
+func rolesFromSet(ctx context.Context, roles types.Set) ([]userRoleModel, diag.Diagnostics) {
+	if roles.IsNull() || roles.IsUnknown() {
+		return nil, nil
+	}
+
+	result := []userRoleModel{}
+	diags := roles.ElementsAs(ctx, &result, false)
+
+	return result, diags
+}
+
+// rolesToSet converts the roles returned by usersInfo to a roles set.
+func rolesToSet(ctx context.Context, roles []dbRole) (types.Set, diag.Diagnostics) {
+	elements := make([]userRoleModel, 0, len(roles))
+	for _, role := range roles {
+		elements = append(elements, userRoleModel{
+			Db:   types.StringValue(role.Db),
+			Role: types.StringValue(role.Role),
+		})
+	}
+
+	return types.SetValueFrom(ctx, roleObjectType, elements)
+}
+
+// roleFromValue converts an element of a roles set, which may hold unknown
+// values while planning, to a userRoleModel.
+func roleFromValue(value attr.Value) (userRoleModel, bool) {
+	object, ok := value.(types.Object)
+	if !ok || object.IsNull() || object.IsUnknown() {
+		return userRoleModel{}, false
+	}
+
+	db, _ := object.Attributes()["db"].(types.String)
+	role, _ := object.Attributes()["role"].(types.String)
+
+	return userRoleModel{Db: db, Role: role}, true
+}
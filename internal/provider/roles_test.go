@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestRolesFromSet(t *testing.T) {
+	read := types.ObjectValueMust(roleObjectType.AttrTypes, map[string]attr.Value{
+		"db":   types.StringValue("test"),
+		"role": types.StringValue("read"),
+	})
+
+	testCases := map[string]struct {
+		roles    types.Set
+		expected []userRoleModel
+	}{
+		"null": {
+			roles:    types.SetNull(roleObjectType),
+			expected: nil,
+		},
+		"unknown": {
+			roles:    types.SetUnknown(roleObjectType),
+			expected: nil,
+		},
+		"empty": {
+			roles:    types.SetValueMust(roleObjectType, []attr.Value{}),
+			expected: []userRoleModel{},
+		},
+		"roles": {
+			roles: types.SetValueMust(roleObjectType, []attr.Value{read}),
+			expected: []userRoleModel{
+				{Db: types.StringValue("test"), Role: types.StringValue("read")},
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual, diags := rolesFromSet(context.Background(), testCase.roles)
+			if diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+
+			if !reflect.DeepEqual(actual, testCase.expected) {
+				t.Errorf("expected %v, got %v", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRolesToSet(t *testing.T) {
+	actual, diags := rolesToSet(context.Background(), []dbRole{{Role: "read", Db: "test"}})
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	roles, diags := rolesFromSet(context.Background(), actual)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	expected := []userRoleModel{{Db: types.StringValue("test"), Role: types.StringValue("read")}}
+	if !reflect.DeepEqual(roles, expected) {
+		t.Errorf("expected %v, got %v", expected, roles)
+	}
+}
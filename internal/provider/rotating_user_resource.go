@@ -0,0 +1,608 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var (
+	_ resource.Resource                   = &rotatingUserResource{}
+	_ resource.ResourceWithConfigure      = &rotatingUserResource{}
+	_ resource.ResourceWithModifyPlan     = &rotatingUserResource{}
+	_ resource.ResourceWithValidateConfig = &rotatingUserResource{}
+)
+
+// defaultGracePeriod is how long the previous user keeps its roles after a
+// rotation when grace_period is not set.
+const defaultGracePeriod = time.Hour
+
+func NewRotatingUserResource() resource.Resource {
+	return &rotatingUserResource{}
+}
+
+// rotatingUserResource manages two users, <name>_a and <name>_b, and rotates
+// the credentials between them so applications can switch over before the
+// previous credentials stop working. Commands are run through the
+// userResource helpers.
+type rotatingUserResource struct {
+	users userResource
+}
+
+type rotatingUserResourceModel struct {
+	Id               types.String `tfsdk:"id"`
+	Name             types.String `tfsdk:"name"`
+	Db               types.String `tfsdk:"db"`
+	Roles            types.Set    `tfsdk:"roles"`
+	PasswordKeeper   types.Map    `tfsdk:"password_keeper"`
+	GracePeriod      types.String `tfsdk:"grace_period"`
+	GeneratePassword types.Object `tfsdk:"generate_password"`
+	ActiveUser       types.String `tfsdk:"active_user"`
+	ActivePassword   types.String `tfsdk:"active_password"`
+	PreviousUser     types.String `tfsdk:"previous_user"`
+	RotatedAt        types.String `tfsdk:"rotated_at"`
+}
+
+func (r *rotatingUserResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.users.Configure(ctx, req, resp)
+}
+
+func (r *rotatingUserResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_rotating_user"
+}
+
+func (r *rotatingUserResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Pair of users, <name>_a and <name>_b, with identical roles. Each rotation sets a new password " +
+			"on the inactive user and makes it active, the previous user keeps its roles until grace_period has elapsed. " +
+			"Both users are stamped with the provider owner and cannot be changed or deleted by another owner.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "Placeholder identifier attribute",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"db": schema.StringAttribute{
+				Description: "DB Where the users are registered",
+				Required:    true,
+				Validators: []validator.String{
+					databaseNameValidator{},
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Description: "Base name of the users, suffixed with _a and _b",
+				Required:    true,
+				Validators: []validator.String{
+					userNameValidator{},
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"roles": rolesAttribute("Set of roles that the users have", true),
+			"password_keeper": schema.MapAttribute{
+				Description: "Arbitrary values that rotate the credentials when changed",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"grace_period": schema.StringAttribute{
+				Description: "How long the previous user keeps its roles after a rotation, defaults to \"1h\"",
+				Optional:    true,
+				Validators: []validator.String{
+					durationValidator{},
+				},
+			},
+			"active_user": schema.StringAttribute{
+				Description: "Name of the user applications should connect with",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"active_password": schema.StringAttribute{
+				Description: "Password of active_user",
+				Computed:    true,
+				Sensitive:   true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"previous_user": schema.StringAttribute{
+				Description: "User that was active before the last rotation, unset once its roles are revoked. " +
+					"The credentials cannot be rotated again while it is set and grace_period has not elapsed",
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"rotated_at": schema.StringAttribute{
+				Description: "RFC 3339 timestamp of the last rotation",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"generate_password": passwordGeneratorBlock("Settings for the generated passwords"),
+		},
+	}
+}
+
+func (r *rotatingUserResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config rotatingUserResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(validatePasswordGenerator(ctx, config.GeneratePassword)...)
+}
+
+func (r *rotatingUserResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan rotatingUserResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The users have no authentication restrictions.
+	resp.Diagnostics.Append(r.users.policy.check(plan.Db, plan.Roles, false)...)
+
+	// Nothing to rotate on create.
+	if req.State.Raw.IsNull() {
+		return
+	}
+
+	var state rotatingUserResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !plan.PasswordKeeper.Equal(state.PasswordKeeper) {
+		// Rotating again would reset the password of the previous user,
+		// which clients may still be using.
+		if !state.PreviousUser.IsNull() && !gracePeriodElapsed(state.RotatedAt, plan.GracePeriod, time.Now()) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("password_keeper"),
+				"Rotation During Grace Period",
+				"User <"+state.PreviousUser.ValueString()+"> was active until "+state.RotatedAt.ValueString()+
+					" and may still be in use. Rotating again would reset its password, "+
+					"retry once grace_period has elapsed since rotated_at.",
+			)
+			return
+		}
+
+		plan.ActiveUser = types.StringUnknown()
+		plan.ActivePassword = types.StringUnknown()
+		plan.PreviousUser = types.StringUnknown()
+		plan.RotatedAt = types.StringUnknown()
+	} else if !state.PreviousUser.IsNull() && gracePeriodElapsed(state.RotatedAt, plan.GracePeriod, time.Now()) {
+		plan.PreviousUser = types.StringNull()
+	}
+
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, plan)...)
+}
+
+// gracePeriodElapsed reports whether gracePeriod has passed since rotatedAt.
+func gracePeriodElapsed(rotatedAt types.String, gracePeriod types.String, now time.Time) bool {
+	if gracePeriod.IsUnknown() {
+		return false
+	}
+
+	period := defaultGracePeriod
+	if !gracePeriod.IsNull() {
+		parsed, err := time.ParseDuration(gracePeriod.ValueString())
+		if err != nil {
+			return false
+		}
+		period = parsed
+	}
+
+	rotated, err := time.Parse(time.RFC3339, rotatedAt.ValueString())
+	if err != nil {
+		return true
+	}
+
+	return !now.Before(rotated.Add(period))
+}
+
+// rotatingUserNames returns the names of the two users managed for name.
+func rotatingUserNames(name string) (string, string) {
+	return name + "_a", name + "_b"
+}
+
+// inactiveUser returns the user of the pair that is not active.
+func inactiveUser(name string, active string) string {
+	a, b := rotatingUserNames(name)
+	if active == a {
+		return b
+	}
+
+	return a
+}
+
+// managedByCustomData returns the customData of created users, holding the
+// ownership marker like the users of the user resource.
+func (r *rotatingUserResource) managedByCustomData() bson.M {
+	return withProviderCustomData(nil, bson.M{managedByCustomDataKey: r.users.managedByMarker(nil)})
+}
+
+// checkPairOwnership fails when a user of the pair is managed by another
+// owner. With claim, the marker of this owner is stamped on unmarked users.
+// Missing users are skipped, rotations create them again.
+func (r *rotatingUserResource) checkPairOwnership(ctx context.Context, db string, name string, operation string, claim bool) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	a, b := rotatingUserNames(name)
+	for _, user := range []string{a, b} {
+		current, err := r.users.getUserFromDb(ctx, db, user)
+		if err != nil {
+			diags.AddError(
+				"Error reading user from MongoDb",
+				"Could not retrieve user <"+user+"> "+err.Error())
+			return diags
+		}
+
+		if current.User == "" {
+			continue
+		}
+
+		stamp, d := r.users.checkOwnership(current, false, operation)
+		diags.Append(d...)
+		if diags.HasError() {
+			return diags
+		}
+
+		if claim && stamp {
+			diags.Append(r.users.emptyOwnerWarning(user)...)
+
+			err = r.users.setProviderCustomData(ctx, db, user, bson.M{managedByCustomDataKey: r.users.managedByMarker(current.CustomData)})
+			if err != nil {
+				diags.AddError(
+					"Error claiming user",
+					"Could not take over ownership of user <"+user+">, unexpected error: "+err.Error(),
+				)
+				return diags
+			}
+		}
+	}
+
+	return diags
+}
+
+// generateRotatingPassword generates a password with the configured
+// generate_password settings.
+func generateRotatingPassword(ctx context.Context, model rotatingUserResourceModel) (string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	var generator passwordGeneratorModel
+	if !model.GeneratePassword.IsNull() {
+		diags.Append(model.GeneratePassword.As(ctx, &generator, basetypes.ObjectAsOptions{})...)
+		if diags.HasError() {
+			return "", diags
+		}
+	}
+
+	password, err := generator.withDefaults().generate()
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("generate_password"),
+			"Error generating password",
+			"Could not generate password, unexpected error: "+err.Error(),
+		)
+	}
+
+	return password, diags
+}
+
+func (r *rotatingUserResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan rotatingUserResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	active, inactive := rotatingUserNames(plan.Name.ValueString())
+	resp.Diagnostics.Append(r.users.emptyOwnerWarning(active)...)
+	resp.Diagnostics.Append(r.users.emptyOwnerWarning(inactive)...)
+
+	activePassword, diags := generateRotatingPassword(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	inactivePassword, diags := generateRotatingPassword(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	roles, diags := rolesFromSet(ctx, plan.Roles)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The inactive user only gets roles once it is rotated to.
+	for _, user := range []struct {
+		name     string
+		password string
+		roles    []userRoleModel
+	}{
+		{active, activePassword, roles},
+		{inactive, inactivePassword, nil},
+	} {
+		userCreateCommand := bson.D{
+			{Key: "createUser", Value: user.name},
+			{Key: "pwd", Value: user.password},
+			{Key: "roles", Value: rolesToBson(user.roles)},
+			{Key: "customData", Value: r.managedByCustomData()},
+		}
+
+		err := r.users.runUserCommand(ctx, plan.Db.ValueString(), userCreateCommand)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error creating user at Mongo Level",
+				"Could not create user <"+user.name+">, unexpected error: "+err.Error(),
+			)
+			return
+		}
+	}
+
+	plan.Id = types.StringValue(plan.Db.ValueString() + "." + plan.Name.ValueString())
+	plan.ActiveUser = types.StringValue(active)
+	plan.ActivePassword = types.StringValue(activePassword)
+	plan.PreviousUser = types.StringNull()
+	plan.RotatedAt = types.StringValue(time.Now().UTC().Format(time.RFC3339))
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *rotatingUserResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state rotatingUserResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	user, err := r.users.getUserFromDb(ctx, state.Db.ValueString(), state.ActiveUser.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading user from MongoDb",
+			"Could not retrieve user <"+state.ActiveUser.ValueString()+"> "+err.Error())
+		return
+	}
+
+	// Active user not found, the pair needs to be created
+	if user.User == "" {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	state.Roles, diags = rolesToSet(ctx, user.Roles)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The other user of the pair only has roles during the grace period,
+	// whenever it has roles it is the previous user until they are revoked.
+	other := inactiveUser(state.Name.ValueString(), state.ActiveUser.ValueString())
+	otherUser, err := r.users.getUserFromDb(ctx, state.Db.ValueString(), other)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading user from MongoDb",
+			"Could not retrieve user <"+other+"> "+err.Error())
+		return
+	}
+
+	switch {
+	case otherUser.User == "":
+		resp.Diagnostics.AddWarning(
+			"Rotating User Missing",
+			"User <"+other+"> was dropped outside of Terraform, the next rotation creates it again.",
+		)
+		state.PreviousUser = types.StringNull()
+	case len(otherUser.Roles) > 0:
+		state.PreviousUser = types.StringValue(other)
+	default:
+		state.PreviousUser = types.StringNull()
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *rotatingUserResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan rotatingUserResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state rotatingUserResourceModel
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	stateRoles, diags := rolesFromSet(ctx, state.Roles)
+	resp.Diagnostics.Append(diags...)
+	planRoles, diags := rolesFromSet(ctx, plan.Roles)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	db := plan.Db.ValueString()
+	rotating := plan.ActiveUser.IsUnknown()
+
+	resp.Diagnostics.Append(r.checkPairOwnership(ctx, db, plan.Name.ValueString(), "update", true)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if rotating {
+		// Rotate: give the inactive user a new password and the planned
+		// roles, the previously active user keeps working until the grace
+		// period has elapsed.
+		next := inactiveUser(plan.Name.ValueString(), state.ActiveUser.ValueString())
+
+		password, diags := generateRotatingPassword(ctx, plan)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		current, err := r.users.getUserFromDb(ctx, db, next)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error reading user from MongoDb",
+				"Could not retrieve user <"+next+"> "+err.Error())
+			return
+		}
+
+		// A user dropped outside of Terraform is created again.
+		if current.User == "" {
+			userCreateCommand := bson.D{
+				{Key: "createUser", Value: next},
+				{Key: "pwd", Value: password},
+				{Key: "roles", Value: rolesToBson(planRoles)},
+				{Key: "customData", Value: r.managedByCustomData()},
+			}
+
+			err = r.users.runUserCommand(ctx, db, userCreateCommand)
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Error creating user at Mongo Level",
+					"Could not create user <"+next+">, unexpected error: "+err.Error(),
+				)
+				return
+			}
+		} else {
+			err = r.users.changeUserPassword(ctx, db, next, bson.D{{Key: "pwd", Value: password}})
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Error changing user password",
+					"Could not change password of user <"+next+">, unexpected error: "+err.Error(),
+				)
+				return
+			}
+
+			rolesCommand := bson.D{
+				{Key: "updateUser", Value: next},
+				{Key: "roles", Value: rolesToBson(planRoles)},
+			}
+
+			err = r.users.runUserCommand(ctx, db, rolesCommand)
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Error granting roles to user",
+					"Could not grant roles to user <"+next+">, unexpected error: "+err.Error(),
+				)
+				return
+			}
+		}
+
+		plan.PreviousUser = state.ActiveUser
+		plan.ActiveUser = types.StringValue(next)
+		plan.ActivePassword = types.StringValue(password)
+		plan.RotatedAt = types.StringValue(time.Now().UTC().Format(time.RFC3339))
+	}
+
+	// Keep the roles of the users in use identical, the inactive user was
+	// given the planned roles by the rotation above.
+	grant, revoke := diffRoles(stateRoles, planRoles)
+	inUse := []string{state.ActiveUser.ValueString()}
+	if !rotating && !state.PreviousUser.IsNull() && !plan.PreviousUser.IsNull() {
+		inUse = append(inUse, state.PreviousUser.ValueString())
+	}
+
+	for _, user := range inUse {
+		resp.Diagnostics.Append(r.users.applyRoleDelta(ctx, db, user, grant, revoke)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// Grace period elapsed, the previous user stops working. All of its
+	// roles are revoked, including any granted outside of Terraform.
+	if !rotating && !state.PreviousUser.IsNull() && plan.PreviousUser.IsNull() {
+		rolesCommand := bson.D{
+			{Key: "updateUser", Value: state.PreviousUser.ValueString()},
+			{Key: "roles", Value: bson.A{}},
+		}
+
+		err := r.users.runUserCommand(ctx, db, rolesCommand)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error revoking roles from user",
+				"Could not revoke roles from user <"+state.PreviousUser.ValueString()+">, unexpected error: "+err.Error(),
+			)
+			return
+		}
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *rotatingUserResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state rotatingUserResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.checkPairOwnership(ctx, state.Db.ValueString(), state.Name.ValueString(), "delete", false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	a, b := rotatingUserNames(state.Name.ValueString())
+	for _, user := range []string{a, b} {
+		userDeleteCommand := bson.D{{Key: "dropUser", Value: user}}
+
+		mongoResult := r.users.client.Database(state.Db.ValueString()).RunCommand(ctx, userDeleteCommand)
+		if mongoResult.Err() != nil {
+			resp.Diagnostics.AddError(
+				"Error deleting user",
+				"Could not delete user <"+user+">, unexpected error: "+mongoResult.Err().Error(),
+			)
+			return
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccRotatingUserResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + `
+resource "mongodb-users_rotating_user" "test_rotating" {
+  name = "test_rotating"
+  db = "test"
+  grace_period = "1h"
+  password_keeper = {
+    rotation = "1"
+  }
+  roles = [
+    {
+      db = "test"
+      role = "read"
+    }
+  ]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("mongodb-users_rotating_user.test_rotating", "active_user", "test_rotating_a"),
+					resource.TestCheckNoResourceAttr("mongodb-users_rotating_user.test_rotating", "previous_user"),
+					resource.TestCheckResourceAttrSet("mongodb-users_rotating_user.test_rotating", "active_password"),
+					resource.TestCheckResourceAttrSet("mongodb-users_rotating_user.test_rotating", "rotated_at"),
+				),
+			},
+			// Rotation testing
+			{
+				Config: providerConfig + `
+resource "mongodb-users_rotating_user" "test_rotating" {
+  name = "test_rotating"
+  db = "test"
+  grace_period = "1h"
+  password_keeper = {
+    rotation = "2"
+  }
+  roles = [
+    {
+      db = "test"
+      role = "read"
+    }
+  ]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("mongodb-users_rotating_user.test_rotating", "active_user", "test_rotating_b"),
+					resource.TestCheckResourceAttr("mongodb-users_rotating_user.test_rotating", "previous_user", "test_rotating_a"),
+					resource.TestCheckResourceAttr("mongodb-users_rotating_user.test_rotating", "roles.#", "1"),
+				),
+			},
+			// No rotation while the previous user is in its grace period
+			{
+				Config: providerConfig + `
+resource "mongodb-users_rotating_user" "test_rotating" {
+  name = "test_rotating"
+  db = "test"
+  grace_period = "1h"
+  password_keeper = {
+    rotation = "3"
+  }
+  roles = [
+    {
+      db = "test"
+      role = "read"
+    }
+  ]
+}
+`,
+				ExpectError: regexp.MustCompile("Rotation During Grace Period"),
+			},
+			// Elapsed grace period revokes the previous user
+			{
+				Config: providerConfig + `
+resource "mongodb-users_rotating_user" "test_rotating" {
+  name = "test_rotating"
+  db = "test"
+  grace_period = "1s"
+  password_keeper = {
+    rotation = "2"
+  }
+  roles = [
+    {
+      db = "test"
+      role = "read"
+    }
+  ]
+}
+`,
+				PreConfig: func() {
+					time.Sleep(2 * time.Second)
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("mongodb-users_rotating_user.test_rotating", "active_user", "test_rotating_b"),
+					resource.TestCheckNoResourceAttr("mongodb-users_rotating_user.test_rotating", "previous_user"),
+				),
+			},
+		},
+	})
+}
+
+func TestGracePeriodElapsed(t *testing.T) {
+	now := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := map[string]struct {
+		rotatedAt   types.String
+		gracePeriod types.String
+		expected    bool
+	}{
+		"default within grace period": {
+			rotatedAt:   types.StringValue("2024-01-31T23:30:00Z"),
+			gracePeriod: types.StringNull(),
+			expected:    false,
+		},
+		"default grace period elapsed": {
+			rotatedAt:   types.StringValue("2024-01-31T22:00:00Z"),
+			gracePeriod: types.StringNull(),
+			expected:    true,
+		},
+		"configured grace period": {
+			rotatedAt:   types.StringValue("2024-01-31T22:00:00Z"),
+			gracePeriod: types.StringValue("24h"),
+			expected:    false,
+		},
+		"unknown grace period": {
+			rotatedAt:   types.StringValue("2024-01-01T00:00:00Z"),
+			gracePeriod: types.StringUnknown(),
+			expected:    false,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if actual := gracePeriodElapsed(testCase.rotatedAt, testCase.gracePeriod, now); actual != testCase.expected {
+				t.Errorf("expected %t, got %t", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestInactiveUser(t *testing.T) {
+	if actual := inactiveUser("app", "app_a"); actual != "app_b" {
+		t.Errorf("expected app_b, got %s", actual)
+	}
+
+	if actual := inactiveUser("app", "app_b"); actual != "app_a" {
+		t.Errorf("expected app_a, got %s", actual)
+	}
+}
+
+func TestAccRotatingUserResourceOwnership(t *testing.T) {
+	config := func(owner string, rotation string) string {
+		return fmt.Sprintf(`
+provider "mongodb-users" {
+  host = "localhost:27017"
+  username = "root"
+  password = "password123"
+  owner = %q
+}
+
+resource "mongodb-users_rotating_user" "test_rotating_owner" {
+  name = "test_rotating_owner"
+  db = "test"
+  grace_period = "1h"
+  password_keeper = {
+    rotation = %q
+  }
+  roles = [
+    {
+      db = "test"
+      role = "read"
+    }
+  ]
+}
+`, owner, rotation)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: config("stack-a", "1"),
+			},
+			// Another owner cannot rotate the users
+			{
+				Config:      config("stack-b", "2"),
+				ExpectError: regexp.MustCompile("User Managed By Another Owner"),
+			},
+			// The owner still can, and destroy them afterwards
+			{
+				Config: config("stack-a", "2"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("mongodb-users_rotating_user.test_rotating_owner", "active_user", "test_rotating_owner_b"),
+				),
+			},
+		},
+	})
+}
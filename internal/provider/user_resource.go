@@ -2,26 +2,47 @@ package provider
 
 import (
 	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
+	"regexp"
 	"strings"
 	"time"
 
-	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework-jsontypes/jsontypes"
+	"github.com/hashicorp/terraform-plugin-framework-validators/boolvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/auth"
 )
 
 var (
-	_ resource.Resource                = &userResource{}
-	_ resource.ResourceWithConfigure   = &userResource{}
-	_ resource.ResourceWithImportState = &userResource{}
+	_ resource.Resource                   = &userResource{}
+	_ resource.ResourceWithConfigure      = &userResource{}
+	_ resource.ResourceWithImportState    = &userResource{}
+	_ resource.ResourceWithModifyPlan     = &userResource{}
+	_ resource.ResourceWithValidateConfig = &userResource{}
 )
 
 func NewUserResource() resource.Resource {
@@ -29,16 +50,51 @@ func NewUserResource() resource.Resource {
 }
 
 type userResource struct {
-	client *mongo.Client
+	client            *mongo.Client
+	host              string
+	x509MemberSubject string
+	roles             *roleCache
+	policy            *userPolicy
+	passwordPolicy    *passwordPolicy
+	owner             string
+	version           string
+
+	adoptExistingDefault bool
 }
 
 type userResourceModel struct {
-	Id          types.String    `tfsdk:"id"`
-	User        types.String    `tfsdk:"user"`
-	Password    types.String    `tfsdk:"password"`
-	Db          types.String    `tfsdk:"db"`
-	Roles       []userRoleModel `tfsdk:"roles"`
-	LastUpdated types.String    `tfsdk:"last_updated"`
+	Id                types.String         `tfsdk:"id"`
+	User              types.String         `tfsdk:"user"`
+	Password          types.String         `tfsdk:"password"`
+	PasswordWo        types.String         `tfsdk:"password_wo"`
+	PasswordWoVersion types.Int64          `tfsdk:"password_wo_version"`
+	PasswordHash      types.String         `tfsdk:"password_hash"`
+	VerifyPassword    types.Bool           `tfsdk:"verify_password"`
+	GeneratePassword  types.Object         `tfsdk:"generate_password"`
+	PasswordKeeper    types.Map            `tfsdk:"password_keeper"`
+	GeneratedPassword types.String         `tfsdk:"generated_password"`
+	Rotation          types.Object         `tfsdk:"rotation"`
+	LastRotated       types.String         `tfsdk:"last_rotated"`
+	CustomData        jsontypes.Normalized `tfsdk:"custom_data"`
+	Labels            types.Map            `tfsdk:"labels"`
+	Mechanisms        types.Set            `tfsdk:"mechanisms"`
+
+	ExternalIdentityType types.String `tfsdk:"external_identity_type"`
+	CertificatePem       types.String `tfsdk:"certificate_pem"`
+	ForceAdopt           types.Bool   `tfsdk:"force_adopt"`
+	ConflictPolicy       types.String `tfsdk:"conflict_policy"`
+	AdoptExisting        types.Bool   `tfsdk:"adopt_existing"`
+	DeletionProtection   types.Bool   `tfsdk:"deletion_protection"`
+	DeletionPolicy       types.String `tfsdk:"deletion_policy"`
+
+	AuthenticationRestrictions types.List   `tfsdk:"authentication_restrictions"`
+	Db                         types.String `tfsdk:"db"`
+	Roles                      types.Set    `tfsdk:"roles"`
+	LastUpdated                types.String `tfsdk:"last_updated"`
+}
+
+type rotationModel struct {
+	Period types.String `tfsdk:"period"`
 }
 
 type userRoleModel struct {
@@ -46,16 +102,27 @@ type userRoleModel struct {
 	Role types.String `tfsdk:"role"`
 }
 
+// scramMechanisms are the mechanisms accepted by createUser and updateUser.
+var scramMechanisms = []string{"SCRAM-SHA-1", "SCRAM-SHA-256"}
+
+// passwordHashRegex matches the hex encoded MD5 digests accepted by
+// password_hash.
+var passwordHashRegex = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
 type commandResponse struct {
 	OK            int       `bson:"ok"`
 	OperationTime time.Time `bson:"operationTime"`
 }
 
 type dbUser struct {
-	Id    string   `bson:"_id"`
-	User  string   `bson:"user"`
-	Db    string   `bson:"db"`
-	Roles []dbRole `bson:"roles"`
+	Id         string   `bson:"_id"`
+	User       string   `bson:"user"`
+	Db         string   `bson:"db"`
+	Roles      []dbRole `bson:"roles"`
+	CustomData bson.M   `bson:"customData,omitempty"`
+	Mechanisms []string `bson:"mechanisms,omitempty"`
+
+	AuthenticationRestrictions []dbAuthenticationRestriction `bson:"authenticationRestrictions,omitempty"`
 }
 
 type dbRole struct {
@@ -73,18 +140,26 @@ func (r *userResource) Configure(_ context.Context, req resource.ConfigureReques
 		return
 	}
 
-	client, ok := req.ProviderData.(*mongo.Client)
+	providerData, ok := req.ProviderData.(*mongodbUsersProviderData)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected *mongo.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *mongodbUsersProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.client = client
+	r.client = providerData.client
+	r.host = providerData.host
+	r.x509MemberSubject = providerData.x509MemberSubject
+	r.roles = providerData.roles
+	r.policy = providerData.policy
+	r.passwordPolicy = providerData.passwordPolicy
+	r.owner = providerData.owner
+	r.version = providerData.version
+	r.adoptExistingDefault = providerData.adoptExistingUsers
 }
 
 func (r *userResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -99,84 +174,674 @@ func (r *userResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 				Computed:    true,
 			},
 			"db": schema.StringAttribute{
-				Description: "DB Where the user is registered",
+				Description: "DB Where the user is registered, \"" + externalDb + "\" for users authenticated by LDAP, Kerberos, AWS IAM, OIDC or X.509",
 				Required:    true,
+				Validators: []validator.String{
+					databaseNameValidator{},
+				},
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
 			"user": schema.StringAttribute{
-				Description: "Name of user",
-				Required:    true,
+				Description: "Name of user, computed from certificate_pem when it is set",
+				Optional:    true,
+				Computed:    true,
+				Validators: []validator.String{
+					userNameValidator{},
+				},
 				PlanModifiers: []planmodifier.String{
+					certificateSubjectModifier{},
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
+			"certificate_pem": schema.StringAttribute{
+				Description: "PEM encoded client certificate of an X.509 user in the \"" + externalDb + "\" database, the user is its RFC 2253 subject. " +
+					"A new certificate with the same subject does not replace the user",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.ExactlyOneOf(path.MatchRoot("user")),
+				},
+			},
 			"password": schema.StringAttribute{
-				Description: "Password of user, cannot be changed once set",
-				Required:    true,
+				Description: "Password of user, only sent to MongoDB when it changes. Stored in state, use password_wo on Terraform 1.11 and later to avoid that",
+				Optional:    true,
+				Sensitive:   true,
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(
+						path.MatchRoot("password_wo"),
+						path.MatchRoot("password_hash"),
+						path.MatchRoot("generate_password"),
+					),
+				},
+			},
+			"password_wo": schema.StringAttribute{
+				Description: "Write-only password of user, never stored in state. Only sent to MongoDB when password_wo_version changes",
+				Optional:    true,
+				Sensitive:   true,
+				WriteOnly:   true,
+				Validators: []validator.String{
+					stringvalidator.AlsoRequires(path.MatchRoot("password_wo_version")),
+					stringvalidator.ConflictsWith(
+						path.MatchRoot("password_hash"),
+						path.MatchRoot("generate_password"),
+					),
+				},
+			},
+			"password_wo_version": schema.Int64Attribute{
+				Description: "Version of password_wo, change it to send a new password_wo to MongoDB",
+				Optional:    true,
+				Validators: []validator.Int64{
+					int64validator.AlsoRequires(path.MatchRoot("password_wo")),
+				},
+			},
+			"password_hash": schema.StringAttribute{
+				Description: "Pre-hashed password of user, the hex encoded MD5 digest of `<user>:mongo:<password>`. " +
+					"The user is restricted to the SCRAM-SHA-1 mechanism, as MongoDB only accepts client digested passwords for it. " +
+					"A digest made with another username cannot be detected and fails authentication, only the digest of an empty password is rejected",
+				Optional:  true,
+				Sensitive: true,
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(passwordHashRegex, "must be a lowercase hex encoded MD5 digest"),
+					stringvalidator.ConflictsWith(path.MatchRoot("generate_password")),
+				},
+			},
+			"password_keeper": schema.MapAttribute{
+				Description: "Arbitrary values that generate a new password when changed",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"generated_password": schema.StringAttribute{
+				Description: "Password generated by generate_password",
+				Computed:    true,
 				Sensitive:   true,
+				PlanModifiers: []planmodifier.String{
+					generatedPasswordModifier{},
+				},
+			},
+			"verify_password": schema.BoolAttribute{
+				Description: "Authenticate as the user during refresh to detect passwords changed outside of Terraform, " +
+					"a password that fails to authenticate is planned to be reset. Only supported with password",
+				Optional: true,
+				Validators: []validator.Bool{
+					boolvalidator.AlsoRequires(path.MatchRoot("password")),
+				},
+			},
+			"roles": rolesAttribute("Set of roles that the user has, unset to leave the roles of the user to other tools and an empty set for a user without roles", false),
+			"custom_data": schema.StringAttribute{
+				Description: "JSON object stored as the customData of the user, compared semantically so formatting and key order do not cause changes. " +
+					"Cannot contain the \"" + labelsCustomDataKey + "\" and \"" + providerCustomDataKey + "\" keys, which are managed by the provider",
+				CustomType: jsontypes.NormalizedType{},
+				Optional:   true,
+			},
+			"labels": schema.MapAttribute{
+				Description: "Labels stored as the \"" + labelsCustomDataKey + "\" object in the customData of the user",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"external_identity_type": schema.StringAttribute{
+				Description: "Kind of external identity of a \"" + externalDb + "\" user, validates the format of user. " +
+					"One of " + strings.Join(externalIdentityTypes, ", "),
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(externalIdentityTypes...),
+				},
 			},
-			"roles": schema.SetAttribute{
-				ElementType: types.ObjectType{
-					AttrTypes: map[string]attr.Type{
-						"db":   types.StringType,
-						"role": types.StringType,
+			"mechanisms": schema.SetAttribute{
+				Description: "SCRAM mechanisms the user can authenticate with, defaults to the server default. " +
+					"Changing them resends the password, which has to be available from password, password_wo, password_hash or generate_password",
+				ElementType: types.StringType,
+				Optional:    true,
+				Computed:    true,
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+					setvalidator.ValueStringsAre(stringvalidator.OneOf(scramMechanisms...)),
+				},
+				PlanModifiers: []planmodifier.Set{
+					setplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"adopt_existing": schema.BoolAttribute{
+				Description: "Adopt the user when it already exists instead of failing, replacing its password, roles, authentication restrictions and custom data. " +
+					"Defaults to the adopt_existing_users provider attribute",
+				Optional: true,
+			},
+			"force_adopt": schema.BoolAttribute{
+				Description: "Update and delete the user even when it is managed by another provider owner, taking over its ownership",
+				Optional:    true,
+			},
+			"authentication_restrictions": schema.ListNestedAttribute{
+				Description: "Restrictions on where the user may authenticate from, " +
+					"the user may authenticate when any of the restrictions is satisfied. Unset to leave the restrictions of the user to other tools",
+				Optional: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"client_source": schema.ListAttribute{
+							Description: "IP addresses or CIDR ranges the client has to connect from",
+							ElementType: types.StringType,
+							Optional:    true,
+							Validators: []validator.List{
+								listvalidator.SizeAtLeast(1),
+								listvalidator.ValueStringsAre(cidrValidator{}),
+								listvalidator.AtLeastOneOf(path.MatchRelative().AtParent().AtName("server_address")),
+							},
+						},
+						"server_address": schema.ListAttribute{
+							Description: "IP addresses or CIDR ranges of the server the client has to connect to",
+							ElementType: types.StringType,
+							Optional:    true,
+							Validators: []validator.List{
+								listvalidator.SizeAtLeast(1),
+								listvalidator.ValueStringsAre(cidrValidator{}),
+							},
+						},
 					},
 				},
-				Description: "Set of roles that the user has",
-				Required:    true,
+			},
+			"conflict_policy": schema.StringAttribute{
+				Description: "What to do when roles, authentication_restrictions, custom_data or labels were changed outside of Terraform since the last refresh. " +
+					"\"" + conflictPolicyFail + "\" fails the update, the default, \"" + conflictPolicyOverwrite + "\" overwrites the changes",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(conflictPolicyFail, conflictPolicyOverwrite),
+				},
+			},
+			"deletion_protection": schema.BoolAttribute{
+				Description: "Fail plans that destroy or replace the user, e.g. for shared service accounts",
+				Optional:    true,
+			},
+			"deletion_policy": schema.StringAttribute{
+				Description: "What to do with the user when the resource is destroyed. " +
+					"\"" + deletionPolicyDelete + "\" drops it, the default, \"" + deletionPolicyAbandon + "\" only removes it from state and leaves it in MongoDB",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(deletionPolicyDelete, deletionPolicyAbandon),
+				},
+			},
+			"last_rotated": schema.StringAttribute{
+				Description: "RFC 3339 timestamp of the last password change, kept in the customData of the user",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"last_updated": schema.StringAttribute{
 				Computed:    true,
 				Description: "Timestamp of the last Terraform update of the order.",
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"rotation": schema.SingleNestedBlock{
+				Description: "Rotate the password once period has elapsed since last_rotated. " +
+					"Only a generated password is rotated by the plan, for password and password_wo rotation is advisory " +
+					"as the provider cannot change configured values, a warning asks for a new password or password_wo_version",
+				Attributes: map[string]schema.Attribute{
+					"period": schema.StringAttribute{
+						Description: "Maximum age of the password as a duration, e.g. \"720h\"",
+						Required:    true,
+						Validators: []validator.String{
+							durationValidator{},
+						},
+					},
+				},
+			},
+			"generate_password": passwordGeneratorBlock("Generate the password of the user on create, exposed as generated_password"),
+		},
 	}
 }
 
-func (r *userResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	var plan userResourceModel
-	diags := req.Plan.Get(ctx, &plan)
+func (r *userResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config userResourceModel
+	diags := req.Config.Get(ctx, &config)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	var roles []bson.M
-	for _, role := range plan.Roles {
-		roles = append(roles, bson.M{"role": role.Role.ValueString(), "db": role.Db.ValueString()})
+	resp.Diagnostics.Append(validatePasswordGenerator(ctx, config.GeneratePassword)...)
+
+	if !config.Db.IsUnknown() {
+		resp.Diagnostics.Append(validateCredentials(ctx, req.Config, config)...)
 	}
 
-	userCreateCommand := bson.D{{"createUser", plan.User.ValueString()}, {"pwd", plan.Password.ValueString()}, {"roles", roles}}
+	if !config.CustomData.IsNull() && !config.CustomData.IsUnknown() {
+		var customData map[string]interface{}
+		err := json.Unmarshal([]byte(config.CustomData.ValueString()), &customData)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("custom_data"),
+				"Invalid Custom Data",
+				"The custom_data must be a JSON object: "+err.Error(),
+			)
+		}
 
-	mongoResult := r.client.Database(plan.Db.ValueString()).RunCommand(ctx, userCreateCommand)
-	if mongoResult.Err() != nil {
-		resp.Diagnostics.AddError(
-			"Error creating user at Mongo Level",
-			"Could not create user, unexpected error: "+mongoResult.Err().Error(),
+		for _, key := range []string{labelsCustomDataKey, providerCustomDataKey} {
+			if _, ok := customData[key]; ok {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("custom_data"),
+					"Reserved Custom Data Key",
+					"The custom_data cannot contain the \""+key+"\" key, it is managed by the provider.",
+				)
+			}
+		}
+	}
+
+	if !config.PasswordHash.IsNull() && !config.Mechanisms.IsNull() && !config.Mechanisms.IsUnknown() {
+		var mechanisms []string
+		resp.Diagnostics.Append(config.Mechanisms.ElementsAs(ctx, &mechanisms, true)...)
+		if len(mechanisms) != 1 || mechanisms[0] != "SCRAM-SHA-1" {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("mechanisms"),
+				"Invalid Mechanisms",
+				"A password_hash can only be used with the SCRAM-SHA-1 mechanism, set mechanisms to [\"SCRAM-SHA-1\"] or remove it.",
+			)
+		}
+	}
+
+	// The digest cannot be checked against the username without the password,
+	// only the digest of an empty password for this user is recognizable.
+	if !config.PasswordHash.IsNull() && !config.PasswordHash.IsUnknown() && !config.User.IsUnknown() {
+		if config.PasswordHash.ValueString() == passwordHash(config.User.ValueString(), "") {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("password_hash"),
+				"Empty Password Hash",
+				"The password_hash is the digest of an empty password for user <"+config.User.ValueString()+">. "+
+					"Generate it as the hex encoded MD5 of \"<user>:mongo:<password>\" using the username of this resource.",
+			)
+		}
+	}
+}
+
+// validateCredentials checks that users in the $external database have no
+// password and all other users have one.
+func validateCredentials(ctx context.Context, config tfsdk.Config, model userResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	var passwordWo types.String
+	diags.Append(config.GetAttribute(ctx, path.Root("password_wo"), &passwordWo)...)
+
+	credentials := map[string]bool{
+		"password":          !model.Password.IsNull(),
+		"password_wo":       !passwordWo.IsNull(),
+		"password_hash":     !model.PasswordHash.IsNull(),
+		"generate_password": !model.GeneratePassword.IsNull(),
+		"mechanisms":        !model.Mechanisms.IsNull(),
+		"verify_password":   !model.VerifyPassword.IsNull(),
+		"password_keeper":   !model.PasswordKeeper.IsNull(),
+		"rotation":          !model.Rotation.IsNull(),
+	}
+
+	if model.Db.ValueString() == externalDb {
+		for _, attribute := range []string{"password", "password_wo", "password_hash", "generate_password", "mechanisms", "verify_password", "password_keeper", "rotation"} {
+			if credentials[attribute] {
+				diags.AddAttributeError(
+					path.Root(attribute),
+					"Invalid External User",
+					"Users in the \""+externalDb+"\" database authenticate with an external identity provider, "+attribute+" cannot be set.",
+				)
+			}
+		}
+
+		if !model.CertificatePem.IsNull() && !model.CertificatePem.IsUnknown() {
+			if _, err := certificateSubject(model.CertificatePem.ValueString()); err != nil {
+				diags.AddAttributeError(
+					path.Root("certificate_pem"),
+					"Invalid Certificate",
+					"Could not read the subject of certificate_pem: "+err.Error(),
+				)
+			}
+		}
+
+		if !model.CertificatePem.IsNull() && !model.ExternalIdentityType.IsNull() && model.ExternalIdentityType.ValueString() != externalIdentityX509 {
+			diags.AddAttributeError(
+				path.Root("external_identity_type"),
+				"Invalid External Identity Type",
+				"Users with a certificate_pem are X.509 users, external_identity_type has to be \""+externalIdentityX509+"\" or unset.",
+			)
+		}
+
+		if !model.ExternalIdentityType.IsNull() && !model.ExternalIdentityType.IsUnknown() && !model.User.IsNull() && !model.User.IsUnknown() {
+			err := validateExternalUser(model.ExternalIdentityType.ValueString(), model.User.ValueString())
+			if err != nil {
+				diags.AddAttributeError(
+					path.Root("user"),
+					"Invalid External User",
+					"The user is not a valid "+model.ExternalIdentityType.ValueString()+" identity: "+err.Error(),
+				)
+			}
+		}
+
+		return diags
+	}
+
+	if !credentials["password"] && !credentials["password_wo"] && !credentials["password_hash"] && !credentials["generate_password"] {
+		diags.AddAttributeError(
+			path.Root("password"),
+			"Missing Password",
+			"One of password, password_wo, password_hash or generate_password is required for users outside of the \""+externalDb+"\" database.",
 		)
+	}
+
+	if !model.ExternalIdentityType.IsNull() {
+		diags.AddAttributeError(
+			path.Root("external_identity_type"),
+			"Invalid External Identity Type",
+			"The external_identity_type can only be set for users in the \""+externalDb+"\" database.",
+		)
+	}
+
+	if !model.CertificatePem.IsNull() {
+		diags.AddAttributeError(
+			path.Root("certificate_pem"),
+			"Invalid Certificate",
+			"The certificate_pem can only be set for X.509 users in the \""+externalDb+"\" database.",
+		)
+	}
+
+	return diags
+}
+
+func (r *userResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Destroying a protected user would fail halfway through the apply.
+	if req.Plan.Raw.IsNull() {
+		if !req.State.Raw.IsNull() {
+			var state userResourceModel
+			resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+
+			resp.Diagnostics.Append(deletionProtected(state)...)
+		}
 		return
 	}
 
-	var response commandResponse
-	err := mongoResult.Decode(&response)
+	var plan userResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.validateX509User(plan)...)
+	resp.Diagnostics.Append(r.checkCustomRoles(ctx, plan)...)
+	resp.Diagnostics.Append(r.policy.check(plan.Db, plan.Roles, authenticationRestricted(plan.AuthenticationRestrictions))...)
+
+	// Rotation only applies to existing users.
+	if req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(r.checkPlannedPassword(ctx, plan, true)...)
+		return
+	}
+
+	var state userResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// So would replacing it. RequiresReplace of the attribute plan modifiers
+	// is not passed to ModifyPlan, so the attributes are compared here.
+	if (!plan.User.IsUnknown() && !plan.User.Equal(state.User)) || (!plan.Db.IsUnknown() && !plan.Db.Equal(state.Db)) {
+		resp.Diagnostics.Append(deletionProtected(state)...)
+	}
+
+	resp.Diagnostics.Append(r.summarizeRoleChanges(ctx, state, plan)...)
+	resp.Diagnostics.Append(r.checkPlannedPassword(ctx, plan, !plan.Password.Equal(state.Password))...)
+
+	if !plan.Rotation.IsNull() && !plan.Rotation.IsUnknown() {
+		var rotation rotationModel
+		resp.Diagnostics.Append(plan.Rotation.As(ctx, &rotation, basetypes.ObjectAsOptions{})...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		lastRotated := state.LastRotated.ValueString()
+		if lastRotated == "" {
+			lastRotated = "an unknown time"
+		}
+
+		period, err := time.ParseDuration(rotation.Period.ValueString())
+		if err == nil && rotationDue(state.LastRotated, period, time.Now()) {
+			// Only generated passwords are computed, a password or password_wo
+			// from the configuration cannot be planned to change.
+			switch {
+			case !plan.GeneratePassword.IsNull():
+				plan.GeneratedPassword = types.StringUnknown()
+			case !plan.PasswordWoVersion.IsNull():
+				resp.Diagnostics.AddAttributeWarning(
+					path.Root("password_wo_version"),
+					"Password Rotation Due",
+					"The password of user <"+plan.User.ValueString()+"> was last rotated at "+lastRotated+
+						", more than "+period.String()+" ago. Set a new password_wo and increase password_wo_version to rotate it.",
+				)
+			default:
+				resp.Diagnostics.AddAttributeWarning(
+					path.Root("password"),
+					"Password Rotation Due",
+					"The password of user <"+plan.User.ValueString()+"> was last rotated at "+lastRotated+
+						", more than "+period.String()+" ago. Set a new password to rotate it.",
+				)
+			}
+		}
+	}
+
+	if passwordChanged(state, plan) {
+		plan.LastRotated = types.StringUnknown()
+	} else if mechanismsChanged(state, plan) {
+		var passwordWo types.String
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("password_wo"), &passwordWo)...)
+
+		if plan.Password.IsNull() && plan.GeneratedPassword.IsNull() && plan.PasswordHash.IsNull() && passwordWo.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("mechanisms"),
+				"Password Not Available",
+				"Changing the mechanisms of user <"+plan.User.ValueString()+"> requires its password, which is not known to the provider. "+
+					"Set password, password_wo or password_hash along with the new mechanisms.",
+			)
+		}
+	}
+
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, plan)...)
+}
+
+// validateX509User rejects X.509 users MongoDB would take for a cluster
+// member, when the provider knows the subject of the member certificates.
+func (r *userResource) validateX509User(plan userResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	x509 := !plan.CertificatePem.IsNull() || plan.ExternalIdentityType.ValueString() == externalIdentityX509
+	if r.x509MemberSubject == "" || plan.Db.ValueString() != externalDb || !x509 || plan.User.IsUnknown() {
+		return diags
+	}
+
+	member, err := isClusterMemberSubject(r.x509MemberSubject, plan.User.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error creating user via Mongo Response Decode Problem",
-			"Could not create user, unexpected error: "+err.Error(),
+		diags.AddAttributeError(
+			path.Root("user"),
+			"Invalid External User",
+			"The user is not a valid "+externalIdentityX509+" identity: "+err.Error(),
+		)
+		return diags
+	}
+
+	if member {
+		diags.AddAttributeError(
+			path.Root("user"),
+			"X.509 User Collides With Cluster Members",
+			"The subject <"+plan.User.ValueString()+"> has the same O, OU and DC attributes as the cluster member certificates <"+
+				r.x509MemberSubject+">, MongoDB would authenticate it as a cluster member. Use a different O, OU or DC for client certificates.",
 		)
+	}
+
+	return diags
+}
+
+// rotationDue reports whether period has elapsed since lastRotated. Users
+// without a known rotation timestamp are always due.
+func rotationDue(lastRotated types.String, period time.Duration, now time.Time) bool {
+	if lastRotated.IsNull() || lastRotated.IsUnknown() {
+		return true
+	}
+
+	rotated, err := time.Parse(time.RFC3339, lastRotated.ValueString())
+	if err != nil {
+		return true
+	}
+
+	return !now.Before(rotated.Add(period))
+}
+
+// generatePasswordAttempts bounds how often generatePassword regenerates a
+// password that breaks the password policy, e.g. by containing a short
+// username by chance.
+const generatePasswordAttempts = 100
+
+// generatePassword fills in a planned generated_password that is unknown,
+// which happens on create and when password_keeper changed. Passwords are
+// regenerated until they meet the password policy.
+func (r *userResource) generatePassword(ctx context.Context, plan *userResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if !plan.GeneratedPassword.IsUnknown() {
+		return diags
+	}
 
+	var generator passwordGeneratorModel
+	diags.Append(plan.GeneratePassword.As(ctx, &generator, basetypes.ObjectAsOptions{})...)
+	if diags.HasError() {
+		return diags
+	}
+
+	var policyErr error
+	for attempt := 0; attempt < generatePasswordAttempts; attempt++ {
+		password, err := generator.withDefaults().generate()
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("generate_password"),
+				"Error generating password",
+				"Could not generate password, unexpected error: "+err.Error(),
+			)
+			return diags
+		}
+
+		policyErr = r.passwordPolicy.check(password, plan.User.ValueString(), plan.Db.ValueString())
+		if policyErr == nil {
+			plan.GeneratedPassword = types.StringValue(password)
+			return diags
+		}
+
+		policyErr = errors.New(redactPassword(policyErr.Error(), password))
+	}
+
+	diags.AddAttributeError(
+		path.Root("generate_password"),
+		"Password Policy Violation",
+		fmt.Sprintf("Could not generate a password meeting the provider password policy in %d attempts, last error: %s", generatePasswordAttempts, policyErr),
+	)
+
+	return diags
+}
+
+func (r *userResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan userResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	if response.OK != 1 {
-		resp.Diagnostics.AddError(
-			"Error creating user via Mongo Response Code",
-			fmt.Sprintf("Could not create user, unexpected error returned from MongoDB: %d", response.OK))
+	resp.Diagnostics.Append(r.generatePassword(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Users in $external authenticate elsewhere and have no password.
+	var credential bson.D
+	if plan.Db.ValueString() != externalDb {
+		resp.Diagnostics.Append(r.enforcePasswordPolicy(ctx, req.Config, plan)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		credential, diags = passwordFields(ctx, req.Config, plan)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	restrictionModels, diags := authenticationRestrictionsFromList(ctx, plan.AuthenticationRestrictions)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	restrictions, diags := authenticationRestrictionsToBson(ctx, restrictionModels)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	customData, diags := userCustomData(ctx, plan, nil)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	roles, diags := rolesFromSet(ctx, plan.Roles)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	metadata := bson.M{managedByCustomDataKey: r.managedByMarker(nil)}
+	resp.Diagnostics.Append(r.emptyOwnerWarning(plan.User.ValueString())...)
+	lastRotated := types.StringNull()
+	if credential != nil {
+		lastRotated = types.StringValue(time.Now().UTC().Format(time.RFC3339))
+		metadata["lastRotated"] = lastRotated.ValueString()
+	}
+
+	userCreateCommand := bson.D{{Key: "createUser", Value: plan.User.ValueString()}}
+	userCreateCommand = append(userCreateCommand, credential...)
+	userCreateCommand = append(userCreateCommand,
+		bson.E{Key: "roles", Value: rolesToBson(roles)},
+		bson.E{Key: "authenticationRestrictions", Value: restrictions},
+		bson.E{Key: "customData", Value: withProviderCustomData(customData, metadata)},
+	)
+
+	mongoResult := r.client.Database(plan.Db.ValueString()).RunCommand(ctx, userCreateCommand)
+	if isUserAlreadyExists(mongoResult.Err()) && r.adoptExisting(plan) {
+		resp.Diagnostics.Append(r.adoptUser(ctx, plan, userCreateCommand)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	} else {
+		if mongoResult.Err() != nil {
+			resp.Diagnostics.AddError(
+				"Error creating user at Mongo Level",
+				"Could not create user, unexpected error: "+mongoResult.Err().Error(),
+			)
+			return
+		}
+
+		var response commandResponse
+		err := mongoResult.Decode(&response)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error creating user via Mongo Response Decode Problem",
+				"Could not create user, unexpected error: "+err.Error(),
+			)
+
+			return
+		}
+
+		if response.OK != 1 {
+			resp.Diagnostics.AddError(
+				"Error creating user via Mongo Response Code",
+				fmt.Sprintf("Could not create user, unexpected error returned from MongoDB: %d", response.OK))
+			return
+		}
+	}
+
 	// Read back user from DB to get ID
 	user, err := r.getUserFromDb(ctx, plan.Db.ValueString(), plan.User.ValueString())
 	if err != nil {
@@ -187,6 +852,9 @@ func (r *userResource) Create(ctx context.Context, req resource.CreateRequest, r
 
 	// Set state to fully populated data
 	plan.Id = types.StringValue(user.Id)
+	plan.Mechanisms, diags = types.SetValueFrom(ctx, types.StringType, user.Mechanisms)
+	resp.Diagnostics.Append(diags...)
+	plan.LastRotated = lastRotated
 	plan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
 
 	diags = resp.State.Set(ctx, plan)
@@ -198,10 +866,13 @@ func (r *userResource) Create(ctx context.Context, req resource.CreateRequest, r
 
 func (r *userResource) getUserFromDb(ctx context.Context, db string, user string) (dbUser, error) {
 	var usersInfo readResponse
-	cmd := bson.D{{Key: "usersInfo", Value: bson.M{
-		"user": user,
-		"db":   db,
-	}}}
+	cmd := bson.D{
+		{Key: "usersInfo", Value: bson.M{
+			"user": user,
+			"db":   db,
+		}},
+		{Key: "showAuthenticationRestrictions", Value: true},
+	}
 
 	err := r.client.Database(db).RunCommand(ctx, cmd).Decode(&usersInfo)
 	if err != nil {
@@ -237,16 +908,59 @@ func (r *userResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
+	// Unset roles and authentication restrictions are managed elsewhere,
+	// unless the user is being imported.
+	importing := state.Id.IsNull()
+
 	state.Id = types.StringValue(user.Id)
 	state.User = types.StringValue(user.User)
 	state.Db = types.StringValue(user.Db)
 
-	state.Roles = []userRoleModel{}
-	for _, item := range user.Roles {
-		state.Roles = append(state.Roles, userRoleModel{
-			Db:   types.StringValue(item.Db),
-			Role: types.StringValue(item.Role),
-		})
+	if !state.Roles.IsNull() || importing {
+		state.Roles, diags = rolesToSet(ctx, user.Roles)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	if !state.AuthenticationRestrictions.IsNull() || importing {
+		state.AuthenticationRestrictions, diags = authenticationRestrictionsToList(ctx, user.AuthenticationRestrictions)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	state.Mechanisms, diags = types.SetValueFrom(ctx, types.StringType, user.Mechanisms)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state.CustomData, state.Labels, diags = customDataFromDb(ctx, user.CustomData, state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state.LastRotated = types.StringNull()
+	if lastRotated, ok := providerCustomData(user.CustomData)["lastRotated"].(string); ok {
+		state.LastRotated = types.StringValue(lastRotated)
+	}
+
+	// Forget a password that no longer authenticates, so the next plan
+	// resets it to the configured value.
+	if state.VerifyPassword.ValueBool() && !state.Password.IsNull() {
+		err = r.verifyPassword(ctx, state.Db.ValueString(), state.User.ValueString(), state.Password.ValueString())
+		if err != nil {
+			tflog.Warn(ctx, "Password of user drifted, it will be reset on the next apply", map[string]interface{}{
+				"db":     state.Db.ValueString(),
+				"user":   state.User.ValueString(),
+				"reason": redactPassword(err.Error(), state.Password.ValueString()),
+			})
+			state.Password = types.StringNull()
+		}
 	}
 
 	diags = resp.State.Set(ctx, &state)
@@ -256,6 +970,58 @@ func (r *userResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	}
 }
 
+// verifyPassword authenticates as user on a short-lived connection. It only
+// returns an error when MongoDB rejected the credentials, failures to reach
+// the server are logged and ignored as they say nothing about the password.
+func (r *userResource) verifyPassword(ctx context.Context, db string, user string, password string) error {
+	verifyCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	credential := options.Credential{
+		AuthSource: db,
+		Username:   user,
+		Password:   password,
+	}
+
+	client, err := mongo.Connect(verifyCtx, options.Client().ApplyURI("mongodb://"+r.host).
+		SetAuth(credential).
+		SetMaxPoolSize(1))
+	if err != nil {
+		tflog.Debug(ctx, "Could not connect to verify password", map[string]interface{}{
+			"reason": redactPassword(err.Error(), password),
+		})
+		return nil
+	}
+
+	defer func() {
+		_ = client.Disconnect(context.Background())
+	}()
+
+	err = client.Ping(verifyCtx, readpref.Primary())
+
+	var authErr *auth.Error
+	if errors.As(err, &authErr) {
+		return err
+	}
+
+	if err != nil {
+		tflog.Debug(ctx, "Could not reach MongoDB to verify password", map[string]interface{}{
+			"reason": redactPassword(err.Error(), password),
+		})
+	}
+
+	return nil
+}
+
+// redactPassword removes password from message before it is logged.
+func redactPassword(message string, password string) string {
+	if password == "" {
+		return message
+	}
+
+	return strings.ReplaceAll(message, password, "<redacted>")
+}
+
 func (r *userResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
 	var plan userResourceModel
 	diags := req.Plan.Get(ctx, &plan)
@@ -264,39 +1030,180 @@ func (r *userResource) Update(ctx context.Context, req resource.UpdateRequest, r
 		return
 	}
 
-	var roles []bson.M
-	for _, role := range plan.Roles {
-		roles = append(roles, bson.M{"role": role.Role.ValueString(), "db": role.Db.ValueString()})
+	var state userResourceModel
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	userUpdateCommand := bson.D{{"updateUser", plan.User.ValueString()}, {"pwd", plan.Password.ValueString()}, {"roles", roles}}
 
-	mongoResult := r.client.Database(plan.Db.ValueString()).RunCommand(ctx, userUpdateCommand)
-	if mongoResult.Err() != nil {
+	current, err := r.getUserFromDb(ctx, plan.Db.ValueString(), plan.User.ValueString())
+	if err != nil {
 		resp.Diagnostics.AddError(
-			"Error updating user",
-			"Could not update user, unexpected error: "+mongoResult.Err().Error(),
-		)
+			"Error reading user from MongoDb",
+			"Could not retrieve user <"+plan.User.ValueString()+"> "+err.Error())
 		return
 	}
 
-	var response commandResponse
-	err := mongoResult.Decode(&response)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error updating user",
-			"Could not update user, unexpected error: "+err.Error(),
-		)
+	claim, diags := r.checkOwnership(current, plan.ForceAdopt.ValueBool(), "update")
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
+	// Fail rather than overwrite changes made since the last refresh.
+	resp.Diagnostics.Append(checkConflicts(ctx, plan, state, current)...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	if response.OK != 1 {
-		resp.Diagnostics.AddError(
-			"Error updating user",
-			fmt.Sprintf("Could not update user, unexpected error returned from MongoDB: %d", response.OK))
+	resp.Diagnostics.Append(r.generatePassword(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Only send the password when it changed, resending it regenerates the
+	// SCRAM credentials and is rejected for users without a password.
+	// Changing the mechanisms needs the password to derive their
+	// credentials.
+	rotated := passwordChanged(state, plan)
+	if rotated {
+		resp.Diagnostics.Append(r.enforcePasswordPolicy(ctx, req.Config, plan)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	if rotated || mechanismsChanged(state, plan) {
+		credential, diags := passwordFields(ctx, req.Config, plan)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		err := r.changeUserPassword(ctx, plan.Db.ValueString(), plan.User.ValueString(), credential)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error changing user password",
+				"Could not change password of user <"+plan.User.ValueString()+">, unexpected error: "+err.Error(),
+			)
+			return
+		}
+
+	}
+
+	if rotated {
+		lastRotated := time.Now().UTC().Format(time.RFC3339)
+		err := r.setProviderCustomData(ctx, plan.Db.ValueString(), plan.User.ValueString(), bson.M{"lastRotated": lastRotated})
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error recording password rotation",
+				"Could not record password rotation of user <"+plan.User.ValueString()+">, unexpected error: "+err.Error(),
+			)
+			return
+		}
+		plan.LastRotated = types.StringValue(lastRotated)
+	}
+
+	restrictionsChanged, diags := authenticationRestrictionsChanged(ctx, state.AuthenticationRestrictions, plan.AuthenticationRestrictions)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	// Unset authentication restrictions are left to whatever else manages them.
+	if restrictionsChanged && !plan.AuthenticationRestrictions.IsNull() {
+		restrictionModels, diags := authenticationRestrictionsFromList(ctx, plan.AuthenticationRestrictions)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		restrictions, diags := authenticationRestrictionsToBson(ctx, restrictionModels)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		restrictionsCommand := bson.D{
+			{Key: "updateUser", Value: plan.User.ValueString()},
+			{Key: "authenticationRestrictions", Value: restrictions},
+		}
+
+		err := r.runUserCommand(ctx, plan.Db.ValueString(), restrictionsCommand)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error updating user authentication restrictions",
+				"Could not update authentication restrictions of user <"+plan.User.ValueString()+">, unexpected error: "+err.Error(),
+			)
+			return
+		}
+	}
+
+	if !plan.CustomData.Equal(state.CustomData) || !plan.Labels.Equal(state.Labels) {
+		current, err := r.getUserFromDb(ctx, plan.Db.ValueString(), plan.User.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error reading user from MongoDb",
+				"Could not retrieve user <"+plan.User.ValueString()+"> "+err.Error())
+			return
+		}
+
+		customData, diags := userCustomData(ctx, plan, current.CustomData)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		customDataCommand := bson.D{
+			{Key: "updateUser", Value: plan.User.ValueString()},
+			{Key: "customData", Value: customData},
+		}
+
+		err = r.runUserCommand(ctx, plan.Db.ValueString(), customDataCommand)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error updating user custom data",
+				"Could not update custom data of user <"+plan.User.ValueString()+">, unexpected error: "+err.Error(),
+			)
+			return
+		}
+	}
+
+	// Stamp unmarked and adopted users so other owners leave them alone.
+	if claim {
+		resp.Diagnostics.Append(r.emptyOwnerWarning(plan.User.ValueString())...)
+
+		err := r.setProviderCustomData(ctx, plan.Db.ValueString(), plan.User.ValueString(), bson.M{managedByCustomDataKey: r.managedByMarker(current.CustomData)})
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error claiming user",
+				"Could not take over ownership of user <"+plan.User.ValueString()+">, unexpected error: "+err.Error(),
+			)
+			return
+		}
+	}
+
+	// Grant before revoking so the user never holds less than the
+	// intersection of the old and new role sets while the update runs.
+	// Unset roles are left to whatever else manages them.
+	if !plan.Roles.IsNull() {
+		stateRoles, diags := rolesFromSet(ctx, state.Roles)
+		resp.Diagnostics.Append(diags...)
+		planRoles, diags := rolesFromSet(ctx, plan.Roles)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		grant, revoke := diffRoles(stateRoles, planRoles)
+
+		resp.Diagnostics.Append(r.applyRoleDelta(ctx, plan.Db.ValueString(), plan.User.ValueString(), grant, revoke)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
 	// Read back user from DB to get ID
 	user, err := r.getUserFromDb(ctx, plan.Db.ValueString(), plan.User.ValueString())
 	if err != nil {
@@ -307,6 +1214,8 @@ func (r *userResource) Update(ctx context.Context, req resource.UpdateRequest, r
 
 	// Set state to fully populated data
 	plan.Id = types.StringValue(user.Id)
+	plan.Mechanisms, diags = types.SetValueFrom(ctx, types.StringType, user.Mechanisms)
+	resp.Diagnostics.Append(diags...)
 	plan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
 
 	diags = resp.State.Set(ctx, plan)
@@ -316,6 +1225,207 @@ func (r *userResource) Update(ctx context.Context, req resource.UpdateRequest, r
 	}
 }
 
+// applyRoleDelta grants and then revokes roles of user, as computed by
+// diffRoles.
+func (r *userResource) applyRoleDelta(ctx context.Context, db string, user string, grant []userRoleModel, revoke []userRoleModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if len(grant) > 0 {
+		grantCommand := bson.D{
+			{Key: "grantRolesToUser", Value: user},
+			{Key: "roles", Value: rolesToBson(grant)},
+		}
+
+		err := r.runUserCommand(ctx, db, grantCommand)
+		if err != nil {
+			diags.AddError(
+				"Error granting roles to user",
+				"Could not grant roles to user <"+user+">, unexpected error: "+err.Error(),
+			)
+			return diags
+		}
+	}
+
+	if len(revoke) > 0 {
+		revokeCommand := bson.D{
+			{Key: "revokeRolesFromUser", Value: user},
+			{Key: "roles", Value: rolesToBson(revoke)},
+		}
+
+		err := r.runUserCommand(ctx, db, revokeCommand)
+		if err != nil {
+			diags.AddError(
+				"Error revoking roles from user",
+				"Could not revoke roles from user <"+user+">, unexpected error: "+err.Error(),
+			)
+			return diags
+		}
+	}
+
+	return diags
+}
+
+// changeUserPassword sets a new password for an existing user, credential
+// holds the password fields as returned by passwordFields.
+func (r *userResource) changeUserPassword(ctx context.Context, db string, user string, credential bson.D) error {
+	passwordCommand := bson.D{{Key: "updateUser", Value: user}}
+	passwordCommand = append(passwordCommand, credential...)
+
+	return r.runUserCommand(ctx, db, passwordCommand)
+}
+
+// configuredPassword returns the password of the user, either from the
+// password or generated_password attributes or from the write-only
+// password_wo attribute, which is only available in the configuration.
+func configuredPassword(ctx context.Context, config tfsdk.Config, plan userResourceModel) (string, diag.Diagnostics) {
+	if !plan.Password.IsNull() {
+		return plan.Password.ValueString(), nil
+	}
+
+	if !plan.GeneratedPassword.IsNull() {
+		return plan.GeneratedPassword.ValueString(), nil
+	}
+
+	var passwordWo types.String
+	diags := config.GetAttribute(ctx, path.Root("password_wo"), &passwordWo)
+
+	return passwordWo.ValueString(), diags
+}
+
+// passwordFields returns the createUser/updateUser fields that set the
+// password of the user. A password_hash is sent as is with digestPassword
+// disabled, which MongoDB only supports for SCRAM-SHA-1.
+func passwordFields(ctx context.Context, config tfsdk.Config, plan userResourceModel) (bson.D, diag.Diagnostics) {
+	if !plan.PasswordHash.IsNull() {
+		return bson.D{
+			{Key: "pwd", Value: plan.PasswordHash.ValueString()},
+			{Key: "digestPassword", Value: false},
+			{Key: "mechanisms", Value: bson.A{"SCRAM-SHA-1"}},
+		}, nil
+	}
+
+	password, diags := configuredPassword(ctx, config, plan)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	if password == "" {
+		diags.AddError(
+			"Password Not Available",
+			"The password of user <"+plan.User.ValueString()+"> is not known to the provider. "+
+				"Set password, password_wo, password_hash or generate_password.",
+		)
+		return nil, diags
+	}
+
+	fields := bson.D{{Key: "pwd", Value: password}}
+	if !plan.Mechanisms.IsNull() && !plan.Mechanisms.IsUnknown() {
+		var mechanisms []string
+		diags.Append(plan.Mechanisms.ElementsAs(ctx, &mechanisms, false)...)
+		fields = append(fields, bson.E{Key: "mechanisms", Value: mechanisms})
+	}
+
+	return fields, diags
+}
+
+// passwordHash returns the digest MongoDB stores for SCRAM-SHA-1 credentials
+// of user, the hex encoded MD5 of "<user>:mongo:<password>".
+func passwordHash(user string, password string) string {
+	digest := md5.Sum([]byte(user + ":mongo:" + password))
+
+	return hex.EncodeToString(digest[:])
+}
+
+// mechanismsChanged reports whether the planned mechanisms differ from the
+// ones the user has, unset mechanisms are left to the server.
+func mechanismsChanged(state userResourceModel, plan userResourceModel) bool {
+	if plan.Mechanisms.IsNull() || plan.Mechanisms.IsUnknown() {
+		return false
+	}
+
+	return !plan.Mechanisms.Equal(state.Mechanisms)
+}
+
+// passwordChanged reports whether the planned password differs from the one
+// last sent to MongoDB. Write-only passwords are never stored, so for those
+// only a change of password_wo_version counts.
+func passwordChanged(state userResourceModel, plan userResourceModel) bool {
+	if !plan.PasswordWoVersion.IsNull() {
+		return !plan.PasswordWoVersion.Equal(state.PasswordWoVersion)
+	}
+
+	if !plan.PasswordHash.IsNull() {
+		return !plan.PasswordHash.Equal(state.PasswordHash)
+	}
+
+	if !plan.GeneratedPassword.IsNull() {
+		return !plan.GeneratedPassword.Equal(state.GeneratedPassword)
+	}
+
+	return !plan.Password.Equal(state.Password)
+}
+
+// runUserCommand runs a user management command against db and checks that
+// MongoDB acknowledged it.
+func (r *userResource) runUserCommand(ctx context.Context, db string, cmd bson.D) error {
+	mongoResult := r.client.Database(db).RunCommand(ctx, cmd)
+	if mongoResult.Err() != nil {
+		return mongoResult.Err()
+	}
+
+	var response commandResponse
+	err := mongoResult.Decode(&response)
+	if err != nil {
+		return err
+	}
+
+	if response.OK != 1 {
+		return fmt.Errorf("unexpected response code returned from MongoDB: %d", response.OK)
+	}
+
+	return nil
+}
+
+// rolesToBson converts roles to the document array expected by the
+// createUser, updateUser, grantRolesToUser and revokeRolesFromUser commands.
+func rolesToBson(roles []userRoleModel) bson.A {
+	result := bson.A{}
+	for _, role := range roles {
+		result = append(result, bson.M{"role": role.Role.ValueString(), "db": role.Db.ValueString()})
+	}
+
+	return result
+}
+
+// diffRoles returns the roles present in plan but not in state, which need to
+// be granted, and the roles present in state but not in plan, which need to be
+// revoked.
+func diffRoles(state []userRoleModel, plan []userRoleModel) (grant []userRoleModel, revoke []userRoleModel) {
+	current := make(map[dbRole]bool, len(state))
+	for _, role := range state {
+		current[dbRole{Role: role.Role.ValueString(), Db: role.Db.ValueString()}] = true
+	}
+
+	planned := make(map[dbRole]bool, len(plan))
+	for _, role := range plan {
+		key := dbRole{Role: role.Role.ValueString(), Db: role.Db.ValueString()}
+		if !planned[key] && !current[key] {
+			grant = append(grant, role)
+		}
+		planned[key] = true
+	}
+
+	for _, role := range state {
+		key := dbRole{Role: role.Role.ValueString(), Db: role.Db.ValueString()}
+		if !planned[key] {
+			revoke = append(revoke, role)
+			planned[key] = true
+		}
+	}
+
+	return grant, revoke
+}
+
 func (r *userResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
 	var state userResourceModel
 	diags := req.State.Get(ctx, &state)
@@ -324,7 +1434,27 @@ func (r *userResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 		return
 	}
 
-	userDeleteCommand := bson.D{{"dropUser", state.User.ValueString()}}
+	drop, diags := checkDeletion(state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || !drop {
+		return
+	}
+
+	current, err := r.getUserFromDb(ctx, state.Db.ValueString(), state.User.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading user from MongoDb",
+			"Could not retrieve user <"+state.User.ValueString()+"> "+err.Error())
+		return
+	}
+
+	_, diags = r.checkOwnership(current, state.ForceAdopt.ValueBool(), "delete")
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	userDeleteCommand := bson.D{{Key: "dropUser", Value: state.User.ValueString()}}
 
 	mongoResult := r.client.Database(state.Db.ValueString()).RunCommand(ctx, userDeleteCommand)
 	if mongoResult.Err() != nil {
@@ -1,13 +1,25 @@
 package provider
 
 import (
-	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+	"go.mongodb.org/mongo-driver/bson"
 )
 
 func TestAccUserResource(t *testing.T) {
 	resource.Test(t, resource.TestCase{
-		ProtoV5ProviderFactories: testAccProtoV5ProviderFactories,
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
 		Steps: []resource.TestStep{
 			// Create and Read testing
 			{
@@ -80,6 +92,1064 @@ resource "mongodb-users_user" "test_1" {
 					resource.TestCheckResourceAttrSet("mongodb-users_user.test_1", "last_updated"),
 				),
 			},
+			// Revoke roles testing
+			{
+				Config: providerConfig + `
+resource "mongodb-users_user" "test_1" {
+  user = "test_1"
+  db = "test"
+  password = "test1"
+  roles = [
+    {
+      db = "test_other"
+      role = "read"
+    }
+  ]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("mongodb-users_user.test_1", "roles.#", "1"),
+					resource.TestCheckResourceAttr("mongodb-users_user.test_1", "roles.0.db", "test_other"),
+					resource.TestCheckResourceAttr("mongodb-users_user.test_1", "roles.0.role", "read"),
+				),
+			},
+			// Password only update testing
+			{
+				Config: providerConfig + `
+resource "mongodb-users_user" "test_1" {
+  user = "test_1"
+  db = "test"
+  password = "test1-changed"
+  roles = [
+    {
+      db = "test_other"
+      role = "read"
+    }
+  ]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("mongodb-users_user.test_1", "password", "test1-changed"),
+					resource.TestCheckResourceAttr("mongodb-users_user.test_1", "roles.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccUserResourceWriteOnlyPassword(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_11_0),
+		},
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + `
+resource "mongodb-users_user" "test_wo" {
+  user = "test_wo"
+  db = "test"
+  password_wo = "test1"
+  password_wo_version = 1
+  roles = [
+    {
+      db = "test"
+      role = "read"
+    }
+  ]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("mongodb-users_user.test_wo", "user", "test_wo"),
+					resource.TestCheckResourceAttr("mongodb-users_user.test_wo", "password_wo_version", "1"),
+					resource.TestCheckNoResourceAttr("mongodb-users_user.test_wo", "password"),
+					resource.TestCheckNoResourceAttr("mongodb-users_user.test_wo", "password_wo"),
+				),
+			},
+			// Password rotation testing
+			{
+				Config: providerConfig + `
+resource "mongodb-users_user" "test_wo" {
+  user = "test_wo"
+  db = "test"
+  password_wo = "test2"
+  password_wo_version = 2
+  roles = [
+    {
+      db = "test"
+      role = "read"
+    }
+  ]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("mongodb-users_user.test_wo", "password_wo_version", "2"),
+					resource.TestCheckNoResourceAttr("mongodb-users_user.test_wo", "password_wo"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccUserResourcePasswordHash(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + `
+resource "mongodb-users_user" "test_hash" {
+  user = "test_hash"
+  db = "test"
+  password_hash = "843dccefc273d9328045b5e1cdbb158f"
+  roles = [
+    {
+      db = "test"
+      role = "read"
+    }
+  ]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("mongodb-users_user.test_hash", "user", "test_hash"),
+					resource.TestCheckResourceAttr("mongodb-users_user.test_hash", "password_hash", "843dccefc273d9328045b5e1cdbb158f"),
+					resource.TestCheckNoResourceAttr("mongodb-users_user.test_hash", "password"),
+				),
+			},
+			// Password change testing
+			{
+				Config: providerConfig + `
+resource "mongodb-users_user" "test_hash" {
+  user = "test_hash"
+  db = "test"
+  password_hash = "079bed0fcc6fafc745c7c51b10a66d18"
+  roles = [
+    {
+      db = "test"
+      role = "read"
+    }
+  ]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("mongodb-users_user.test_hash", "password_hash", "079bed0fcc6fafc745c7c51b10a66d18"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccUserResourceVerifyPassword(t *testing.T) {
+	config := providerConfig + `
+resource "mongodb-users_user" "test_verify" {
+  user = "test_verify"
+  db = "test"
+  password = "test1"
+  verify_password = true
+  roles = [
+    {
+      db = "test"
+      role = "read"
+    }
+  ]
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("mongodb-users_user.test_verify", "password", "test1"),
+				),
+			},
+			// Out of band password change is planned to be reset
+			{
+				PreConfig: func() {
+					testAccMongoCommand(t, "test", bson.D{
+						{Key: "updateUser", Value: "test_verify"},
+						{Key: "pwd", Value: "changed"},
+					})
+				},
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			// Apply resets the password
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("mongodb-users_user.test_verify", "password", "test1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccUserResourceGeneratePassword(t *testing.T) {
+	var generated string
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + `
+resource "mongodb-users_user" "test_generate" {
+  user = "test_generate"
+  db = "test"
+  generate_password {
+    length = 24
+  }
+  password_keeper = {
+    rotation = "1"
+  }
+  roles = [
+    {
+      db = "test"
+      role = "read"
+    }
+  ]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("mongodb-users_user.test_generate", "generate_password.length", "24"),
+					resource.TestCheckResourceAttrWith("mongodb-users_user.test_generate", "generated_password", func(value string) error {
+						if len(value) != 24 {
+							return fmt.Errorf("expected generated password of length 24, got %d", len(value))
+						}
+						generated = value
+						return nil
+					}),
+				),
+			},
+			// Role changes keep the generated password
+			{
+				Config: providerConfig + `
+resource "mongodb-users_user" "test_generate" {
+  user = "test_generate"
+  db = "test"
+  generate_password {
+    length = 24
+  }
+  password_keeper = {
+    rotation = "1"
+  }
+  roles = [
+    {
+      db = "test"
+      role = "readWrite"
+    }
+  ]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrWith("mongodb-users_user.test_generate", "generated_password", func(value string) error {
+						if value != generated {
+							return fmt.Errorf("expected generated password to be kept")
+						}
+						return nil
+					}),
+				),
+			},
+			// Keeper changes generate a new password
+			{
+				Config: providerConfig + `
+resource "mongodb-users_user" "test_generate" {
+  user = "test_generate"
+  db = "test"
+  generate_password {
+    length = 24
+  }
+  password_keeper = {
+    rotation = "2"
+  }
+  roles = [
+    {
+      db = "test"
+      role = "readWrite"
+    }
+  ]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrWith("mongodb-users_user.test_generate", "generated_password", func(value string) error {
+						if value == generated {
+							return fmt.Errorf("expected a new generated password")
+						}
+						return nil
+					}),
+				),
+			},
+		},
+	})
+}
+
+func TestAccUserResourceRotation(t *testing.T) {
+	var generated string
+
+	config := providerConfig + `
+resource "mongodb-users_user" "test_rotation" {
+  user = "test_rotation"
+  db = "test"
+  generate_password {}
+  rotation {
+    period = "2s"
+  }
+  roles = [
+    {
+      db = "test"
+      role = "read"
+    }
+  ]
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("mongodb-users_user.test_rotation", "last_rotated"),
+					resource.TestCheckResourceAttrWith("mongodb-users_user.test_rotation", "generated_password", func(value string) error {
+						generated = value
+						return nil
+					}),
+				),
+			},
+			// Elapsed period regenerates the password
+			{
+				PreConfig: func() {
+					time.Sleep(3 * time.Second)
+				},
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrWith("mongodb-users_user.test_rotation", "generated_password", func(value string) error {
+						if value == generated {
+							return fmt.Errorf("expected the password to be rotated")
+						}
+						return nil
+					}),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:            "mongodb-users_user.test_rotation",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateId:           "test.test_rotation",
+				ImportStateVerifyIgnore: []string{"last_updated", "generate_password", "generated_password", "rotation"},
+			},
 		},
 	})
 }
+
+func TestAccUserResourceAuthenticationRestrictions(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + `
+resource "mongodb-users_user" "test_restricted" {
+  user = "test_restricted"
+  db = "test"
+  password = "test1"
+  roles = [
+    {
+      db = "test"
+      role = "read"
+    }
+  ]
+  authentication_restrictions = [
+    {
+      client_source = ["10.0.0.0/8", "127.0.0.1"]
+    }
+  ]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("mongodb-users_user.test_restricted", "authentication_restrictions.#", "1"),
+					resource.TestCheckResourceAttr("mongodb-users_user.test_restricted", "authentication_restrictions.0.client_source.#", "2"),
+					resource.TestCheckResourceAttr("mongodb-users_user.test_restricted", "authentication_restrictions.0.client_source.0", "10.0.0.0/8"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:            "mongodb-users_user.test_restricted",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateId:           "test.test_restricted",
+				ImportStateVerifyIgnore: []string{"last_updated", "password"},
+			},
+			// Update and Read testing
+			{
+				Config: providerConfig + `
+resource "mongodb-users_user" "test_restricted" {
+  user = "test_restricted"
+  db = "test"
+  password = "test1"
+  roles = [
+    {
+      db = "test"
+      role = "read"
+    }
+  ]
+  authentication_restrictions = [
+    {
+      client_source  = ["10.0.0.0/8"]
+      server_address = ["127.0.0.1"]
+    }
+  ]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("mongodb-users_user.test_restricted", "authentication_restrictions.0.client_source.#", "1"),
+					resource.TestCheckResourceAttr("mongodb-users_user.test_restricted", "authentication_restrictions.0.server_address.0", "127.0.0.1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccUserResourceCustomData(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + `
+resource "mongodb-users_user" "test_custom_data" {
+  user = "test_custom_data"
+  db = "test"
+  password = "test1"
+  custom_data = jsonencode({
+    ticket = "OPS-1"
+    cost   = { center = 42 }
+  })
+  labels = {
+    team = "platform"
+  }
+  roles = [
+    {
+      db = "test"
+      role = "read"
+    }
+  ]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("mongodb-users_user.test_custom_data", "labels.team", "platform"),
+					resource.TestCheckResourceAttrSet("mongodb-users_user.test_custom_data", "custom_data"),
+				),
+			},
+			// Reformatted JSON is not a change
+			{
+				Config: providerConfig + `
+resource "mongodb-users_user" "test_custom_data" {
+  user = "test_custom_data"
+  db = "test"
+  password = "test1"
+  custom_data = <<-EOT
+    {
+      "cost": {"center": 42},
+      "ticket": "OPS-1"
+    }
+  EOT
+  labels = {
+    team = "platform"
+  }
+  roles = [
+    {
+      db = "test"
+      role = "read"
+    }
+  ]
+}
+`,
+				PlanOnly: true,
+			},
+			// ImportState testing
+			{
+				ResourceName:            "mongodb-users_user.test_custom_data",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateId:           "test.test_custom_data",
+				ImportStateVerifyIgnore: []string{"last_updated", "password"},
+			},
+		},
+	})
+}
+
+func TestAccUserResourceMechanisms(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + `
+resource "mongodb-users_user" "test_mechanisms" {
+  user = "test_mechanisms"
+  db = "test"
+  password = "test1"
+  mechanisms = ["SCRAM-SHA-1"]
+  roles = [
+    {
+      db = "test"
+      role = "read"
+    }
+  ]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("mongodb-users_user.test_mechanisms", "mechanisms.#", "1"),
+					resource.TestCheckTypeSetElemAttr("mongodb-users_user.test_mechanisms", "mechanisms.*", "SCRAM-SHA-1"),
+				),
+			},
+			// Migration testing
+			{
+				Config: providerConfig + `
+resource "mongodb-users_user" "test_mechanisms" {
+  user = "test_mechanisms"
+  db = "test"
+  password = "test1"
+  mechanisms = ["SCRAM-SHA-256"]
+  roles = [
+    {
+      db = "test"
+      role = "read"
+    }
+  ]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("mongodb-users_user.test_mechanisms", "mechanisms.#", "1"),
+					resource.TestCheckTypeSetElemAttr("mongodb-users_user.test_mechanisms", "mechanisms.*", "SCRAM-SHA-256"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccUserResourceExternal(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Roles cannot default to the $external database
+			{
+				Config: providerConfig + `
+resource "mongodb-users_user" "test_external" {
+  user = "CN=test_external,OU=services,O=Example"
+  db = "$external"
+  external_identity_type = "x509"
+  roles = [
+    {
+      role = "read"
+    }
+  ]
+}
+`,
+				ExpectError: regexp.MustCompile("Role Database Required"),
+			},
+			// Create and Read testing
+			{
+				Config: providerConfig + `
+resource "mongodb-users_user" "test_external" {
+  user = "CN=test_external,OU=services,O=Example"
+  db = "$external"
+  external_identity_type = "x509"
+  roles = [
+    {
+      db = "test"
+      role = "read"
+    }
+  ]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("mongodb-users_user.test_external", "db", "$external"),
+					resource.TestCheckNoResourceAttr("mongodb-users_user.test_external", "password"),
+					resource.TestCheckNoResourceAttr("mongodb-users_user.test_external", "last_rotated"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:            "mongodb-users_user.test_external",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateId:           "$external.CN=test_external,OU=services,O=Example",
+				ImportStateVerifyIgnore: []string{"last_updated", "external_identity_type"},
+			},
+		},
+	})
+}
+
+func TestAccUserResourceCertificate(t *testing.T) {
+	subject := testSubject("O", "Example", "OU", "services", "CN", "test_certificate")
+	certificateConfig := func(certificatePem string) string {
+		return providerConfig + fmt.Sprintf(`
+resource "mongodb-users_user" "test_certificate" {
+  db = "$external"
+  certificate_pem = %q
+  roles = [
+    {
+      db = "test"
+      role = "read"
+    }
+  ]
+}
+`, certificatePem)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: certificateConfig(testCertificatePem(t, subject)),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("mongodb-users_user.test_certificate", "user", "CN=test_certificate,OU=services,O=Example"),
+				),
+			},
+			// A new certificate for the same subject keeps the user
+			{
+				Config: certificateConfig(testCertificatePem(t, subject)),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("mongodb-users_user.test_certificate", plancheck.ResourceActionUpdate),
+					},
+				},
+			},
+			// A new subject replaces the user
+			{
+				Config: certificateConfig(testCertificatePem(t, testSubject("O", "Example", "OU", "services", "CN", "test_certificate_2"))),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("mongodb-users_user.test_certificate", plancheck.ResourceActionReplace),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("mongodb-users_user.test_certificate", "user", "CN=test_certificate_2,OU=services,O=Example"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccUserResourceShortFormRoles(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + `
+resource "mongodb-users_user" "test_short_roles" {
+  user = "test_short_roles"
+  db = "test"
+  password = "abc123"
+  roles = [
+    {
+      role = "readWrite"
+    },
+    {
+      db = "admin"
+      role = "read"
+    }
+  ]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckTypeSetElemNestedAttrs("mongodb-users_user.test_short_roles", "roles.*", map[string]string{
+						"db":   "test",
+						"role": "readWrite",
+					}),
+					resource.TestCheckTypeSetElemNestedAttrs("mongodb-users_user.test_short_roles", "roles.*", map[string]string{
+						"db":   "admin",
+						"role": "read",
+					}),
+				),
+			},
+			// Roles granted outside of Terraform are left alone when unset
+			{
+				Config: providerConfig + `
+resource "mongodb-users_user" "test_short_roles" {
+  user = "test_short_roles"
+  db = "test"
+  password = "abc123"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("mongodb-users_user.test_short_roles", "roles.#"),
+				),
+			},
+			{
+				PreConfig: func() {
+					testAccMongoCommand(t, "test", bson.D{
+						{Key: "grantRolesToUser", Value: "test_short_roles"},
+						{Key: "roles", Value: bson.A{"dbAdmin"}},
+					})
+				},
+				Config: providerConfig + `
+resource "mongodb-users_user" "test_short_roles" {
+  user = "test_short_roles"
+  db = "test"
+  password = "abc123"
+}
+`,
+				PlanOnly: true,
+			},
+			// An empty set revokes every role
+			{
+				Config: providerConfig + `
+resource "mongodb-users_user" "test_short_roles" {
+  user = "test_short_roles"
+  db = "test"
+  password = "abc123"
+  roles = []
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("mongodb-users_user.test_short_roles", "roles.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccUserResourceComputedRoles(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_4_0),
+		},
+		Steps: []resource.TestStep{
+			// Role names unknown until apply
+			{
+				Config: providerConfig + `
+resource "terraform_data" "role" {
+  input = "readWrite"
+}
+
+resource "mongodb-users_user" "test_computed_roles" {
+  user = "test_computed_roles"
+  db = "test"
+  password = "abc123"
+  roles = [
+    {
+      role = terraform_data.role.output
+    }
+  ]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckTypeSetElemNestedAttrs("mongodb-users_user.test_computed_roles", "roles.*", map[string]string{
+						"db":   "test",
+						"role": "readWrite",
+					}),
+				),
+			},
+			// Whole set unknown until apply
+			{
+				Config: providerConfig + `
+resource "terraform_data" "role" {
+  input = "readWrite"
+}
+
+resource "terraform_data" "roles" {
+  input = [
+    {
+      db   = "test"
+      role = "read"
+    },
+    {
+      db   = "admin"
+      role = terraform_data.role.output
+    }
+  ]
+}
+
+resource "mongodb-users_user" "test_computed_roles" {
+  user = "test_computed_roles"
+  db = "test"
+  password = "abc123"
+  roles = terraform_data.roles.output
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("mongodb-users_user.test_computed_roles", "roles.#", "2"),
+					resource.TestCheckTypeSetElemNestedAttrs("mongodb-users_user.test_computed_roles", "roles.*", map[string]string{
+						"db":   "admin",
+						"role": "readWrite",
+					}),
+				),
+			},
+		},
+	})
+}
+
+func TestAccUserResourceCustomRoles(t *testing.T) {
+	config := func(role string) string {
+		return providerConfig + fmt.Sprintf(`
+resource "mongodb-users_user" "test_custom_roles" {
+  user = "test_custom_roles"
+  db = "test"
+  password = "abc123"
+  roles = [
+    {
+      role = %q
+    }
+  ]
+}
+`, role)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy: func(_ *terraform.State) error {
+			testAccMongoCommand(t, "test", bson.D{{Key: "dropRole", Value: "testCustomRole"}})
+			return nil
+		},
+		Steps: []resource.TestStep{
+			// Missing custom roles only warn while planning, granting them fails
+			{
+				Config:      config("testMissingRole"),
+				ExpectError: regexp.MustCompile("Could not find role"),
+			},
+			// Existing custom roles are granted
+			{
+				PreConfig: func() {
+					testAccMongoCommand(t, "test", bson.D{
+						{Key: "createRole", Value: "testCustomRole"},
+						{Key: "privileges", Value: bson.A{}},
+						{Key: "roles", Value: bson.A{"read"}},
+					})
+				},
+				Config: config("testCustomRole"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckTypeSetElemNestedAttrs("mongodb-users_user.test_custom_roles", "roles.*", map[string]string{
+						"db":   "test",
+						"role": "testCustomRole",
+					}),
+				),
+			},
+		},
+	})
+}
+
+func TestDiffRoles(t *testing.T) {
+	role := func(db string, name string) userRoleModel {
+		return userRoleModel{Db: types.StringValue(db), Role: types.StringValue(name)}
+	}
+
+	testCases := map[string]struct {
+		state  []userRoleModel
+		plan   []userRoleModel
+		grant  []userRoleModel
+		revoke []userRoleModel
+	}{
+		"unchanged": {
+			state: []userRoleModel{role("test", "readWrite")},
+			plan:  []userRoleModel{role("test", "readWrite")},
+		},
+		"added": {
+			state: []userRoleModel{role("test", "readWrite")},
+			plan:  []userRoleModel{role("test", "readWrite"), role("test_other", "read")},
+			grant: []userRoleModel{role("test_other", "read")},
+		},
+		"removed": {
+			state:  []userRoleModel{role("test", "readWrite"), role("test_other", "read")},
+			plan:   []userRoleModel{role("test", "readWrite")},
+			revoke: []userRoleModel{role("test_other", "read")},
+		},
+		"same role on another db": {
+			state:  []userRoleModel{role("test", "read")},
+			plan:   []userRoleModel{role("test_other", "read")},
+			grant:  []userRoleModel{role("test_other", "read")},
+			revoke: []userRoleModel{role("test", "read")},
+		},
+		"from empty": {
+			plan:  []userRoleModel{role("test", "read"), role("test", "read")},
+			grant: []userRoleModel{role("test", "read")},
+		},
+		"to empty": {
+			state:  []userRoleModel{role("test", "read")},
+			revoke: []userRoleModel{role("test", "read")},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			grant, revoke := diffRoles(testCase.state, testCase.plan)
+
+			if !reflect.DeepEqual(grant, testCase.grant) {
+				t.Errorf("expected grant %v, got %v", testCase.grant, grant)
+			}
+
+			if !reflect.DeepEqual(revoke, testCase.revoke) {
+				t.Errorf("expected revoke %v, got %v", testCase.revoke, revoke)
+			}
+		})
+	}
+}
+
+func TestPasswordChanged(t *testing.T) {
+	testCases := map[string]struct {
+		state    userResourceModel
+		plan     userResourceModel
+		expected bool
+	}{
+		"password unchanged": {
+			state:    userResourceModel{Password: types.StringValue("a")},
+			plan:     userResourceModel{Password: types.StringValue("a")},
+			expected: false,
+		},
+		"password changed": {
+			state:    userResourceModel{Password: types.StringValue("a")},
+			plan:     userResourceModel{Password: types.StringValue("b")},
+			expected: true,
+		},
+		"write-only version unchanged": {
+			state:    userResourceModel{Password: types.StringNull(), PasswordWoVersion: types.Int64Value(1)},
+			plan:     userResourceModel{Password: types.StringNull(), PasswordWoVersion: types.Int64Value(1)},
+			expected: false,
+		},
+		"write-only version changed": {
+			state:    userResourceModel{Password: types.StringNull(), PasswordWoVersion: types.Int64Value(1)},
+			plan:     userResourceModel{Password: types.StringNull(), PasswordWoVersion: types.Int64Value(2)},
+			expected: true,
+		},
+		"password hash changed": {
+			state:    userResourceModel{Password: types.StringNull(), PasswordHash: types.StringValue("a")},
+			plan:     userResourceModel{Password: types.StringNull(), PasswordHash: types.StringValue("b")},
+			expected: true,
+		},
+		"password to password hash": {
+			state:    userResourceModel{Password: types.StringValue("a"), PasswordHash: types.StringNull()},
+			plan:     userResourceModel{Password: types.StringNull(), PasswordHash: types.StringValue("b")},
+			expected: true,
+		},
+		"password to write-only": {
+			state:    userResourceModel{Password: types.StringValue("a"), PasswordWoVersion: types.Int64Null()},
+			plan:     userResourceModel{Password: types.StringNull(), PasswordWoVersion: types.Int64Value(1)},
+			expected: true,
+		},
+		"write-only to password": {
+			state:    userResourceModel{Password: types.StringNull(), PasswordWoVersion: types.Int64Value(1)},
+			plan:     userResourceModel{Password: types.StringValue("a"), PasswordWoVersion: types.Int64Null()},
+			expected: true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if actual := passwordChanged(testCase.state, testCase.plan); actual != testCase.expected {
+				t.Errorf("expected %t, got %t", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestPasswordHash(t *testing.T) {
+	expected := "843dccefc273d9328045b5e1cdbb158f"
+
+	if actual := passwordHash("test_hash", "test1"); actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+}
+
+func TestRedactPassword(t *testing.T) {
+	actual := redactPassword("authentication failed for secret", "secret")
+	if strings.Contains(actual, "secret") {
+		t.Errorf("expected password to be redacted, got %q", actual)
+	}
+
+	actual = redactPassword("authentication failed", "")
+	if actual != "authentication failed" {
+		t.Errorf("expected message to be unchanged, got %q", actual)
+	}
+}
+
+func TestRotationDue(t *testing.T) {
+	now := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := map[string]struct {
+		lastRotated types.String
+		period      time.Duration
+		expected    bool
+	}{
+		"never rotated": {
+			lastRotated: types.StringNull(),
+			period:      time.Hour,
+			expected:    true,
+		},
+		"invalid timestamp": {
+			lastRotated: types.StringValue("yesterday"),
+			period:      time.Hour,
+			expected:    true,
+		},
+		"within period": {
+			lastRotated: types.StringValue("2024-01-31T12:00:00Z"),
+			period:      24 * time.Hour,
+			expected:    false,
+		},
+		"period elapsed": {
+			lastRotated: types.StringValue("2024-01-01T00:00:00Z"),
+			period:      24 * time.Hour,
+			expected:    true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if actual := rotationDue(testCase.lastRotated, testCase.period, now); actual != testCase.expected {
+				t.Errorf("expected %t, got %t", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestMechanismsChanged(t *testing.T) {
+	sha1 := types.SetValueMust(types.StringType, []attr.Value{types.StringValue("SCRAM-SHA-1")})
+	sha256 := types.SetValueMust(types.StringType, []attr.Value{types.StringValue("SCRAM-SHA-256")})
+
+	testCases := map[string]struct {
+		state    types.Set
+		plan     types.Set
+		expected bool
+	}{
+		"unchanged": {
+			state:    sha1,
+			plan:     sha1,
+			expected: false,
+		},
+		"changed": {
+			state:    sha1,
+			plan:     sha256,
+			expected: true,
+		},
+		"left to the server": {
+			state:    sha1,
+			plan:     types.SetNull(types.StringType),
+			expected: false,
+		},
+		"unknown": {
+			state:    sha1,
+			plan:     types.SetUnknown(types.StringType),
+			expected: false,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual := mechanismsChanged(userResourceModel{Mechanisms: testCase.state}, userResourceModel{Mechanisms: testCase.plan})
+			if actual != testCase.expected {
+				t.Errorf("expected %t, got %t", testCase.expected, actual)
+			}
+		})
+	}
+}
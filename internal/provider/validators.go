@@ -0,0 +1,193 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+var _ validator.String = durationValidator{}
+
+// durationValidator checks that a string is a positive Go duration such as
+// "720h".
+type durationValidator struct{}
+
+func (v durationValidator) Description(_ context.Context) string {
+	return "value must be a positive duration such as \"720h\""
+}
+
+func (v durationValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v durationValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	duration, err := time.ParseDuration(req.ConfigValue.ValueString())
+	if err == nil && duration <= 0 {
+		err = fmt.Errorf("duration must be positive")
+	}
+
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Duration",
+			fmt.Sprintf("Expected a positive duration such as \"720h\", got %q: %s", req.ConfigValue.ValueString(), err),
+		)
+	}
+}
+
+var _ validator.String = cidrValidator{}
+
+// cidrValidator checks that a string is an IP address or a CIDR range, as
+// accepted in MongoDB authentication restrictions.
+type cidrValidator struct{}
+
+func (v cidrValidator) Description(_ context.Context) string {
+	return "value must be an IP address or CIDR range such as \"10.0.0.0/8\""
+}
+
+func (v cidrValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v cidrValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	value := req.ConfigValue.ValueString()
+	if net.ParseIP(value) != nil {
+		return
+	}
+
+	if _, _, err := net.ParseCIDR(value); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid CIDR",
+			fmt.Sprintf("Expected an IP address or CIDR range such as \"10.0.0.0/8\", got %q", value),
+		)
+	}
+}
+
+// databaseNameInvalidCharacters are the characters MongoDB rejects in
+// database names on any platform.
+const databaseNameInvalidCharacters = "/\\. \"$*<>:|?\x00"
+
+// maxDatabaseNameLength is the maximum length in bytes of a database name.
+const maxDatabaseNameLength = 63
+
+var _ validator.String = databaseNameValidator{}
+
+// databaseNameValidator checks that a string is a valid MongoDB database
+// name. Users cannot be created in the local and config databases, but roles
+// on them can be granted, while "$external" only holds users.
+type databaseNameValidator struct {
+	roles bool
+}
+
+func (v databaseNameValidator) Description(_ context.Context) string {
+	if v.roles {
+		return "value must be a valid MongoDB database name"
+	}
+	return "value must be a valid MongoDB database name other than local and config"
+}
+
+func (v databaseNameValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v databaseNameValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if err := v.validate(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Database Name",
+			err.Error(),
+		)
+	}
+}
+
+func (v databaseNameValidator) validate(db string) error {
+	switch {
+	case db == externalDb && !v.roles:
+		return nil
+	case db == externalDb:
+		return fmt.Errorf("roles cannot be granted on the %q database, it only holds users", externalDb)
+	case (db == "local" || db == "config") && !v.roles:
+		return fmt.Errorf("users cannot be created in the %q database", db)
+	case db == "":
+		return fmt.Errorf("the database name cannot be empty")
+	case len(db) > maxDatabaseNameLength:
+		return fmt.Errorf("the database name %q is longer than %d bytes", db, maxDatabaseNameLength)
+	case strings.ContainsAny(db, databaseNameInvalidCharacters):
+		return fmt.Errorf("the database name %q cannot contain any of /\\. \"$*<>:|? or NUL", db)
+	}
+
+	return nil
+}
+
+var _ validator.String = userNameValidator{}
+
+// userNameValidator checks that a string can be used as a MongoDB username.
+type userNameValidator struct{}
+
+func (v userNameValidator) Description(_ context.Context) string {
+	return "value must be a non-empty username without NUL characters"
+}
+
+func (v userNameValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v userNameValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	value := req.ConfigValue.ValueString()
+	if value == "" || strings.ContainsRune(value, 0) {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Username",
+			fmt.Sprintf("Expected a non-empty username without NUL characters, got %q", value),
+		)
+	}
+}
+
+var _ validator.String = roleNameValidator{}
+
+// roleNameValidator checks that a string is a well formed MongoDB role name.
+type roleNameValidator struct{}
+
+func (v roleNameValidator) Description(_ context.Context) string {
+	return "value must be a non-empty role name without NUL characters, surrounding whitespace or a leading $"
+}
+
+func (v roleNameValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v roleNameValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	value := req.ConfigValue.ValueString()
+	if value == "" || strings.ContainsRune(value, 0) || strings.TrimSpace(value) != value || strings.HasPrefix(value, "$") {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Role Name",
+			fmt.Sprintf("Expected a non-empty role name without NUL characters, surrounding whitespace or a leading $, got %q", value),
+		)
+	}
+}
@@ -0,0 +1,130 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// validateString runs v against value and reports whether it was accepted.
+func validateString(v validator.String, value string) bool {
+	resp := &validator.StringResponse{}
+	v.ValidateString(context.Background(), validator.StringRequest{
+		Path:        path.Root("test"),
+		ConfigValue: types.StringValue(value),
+	}, resp)
+
+	return !resp.Diagnostics.HasError()
+}
+
+func TestDurationValidator(t *testing.T) {
+	testCases := map[string]bool{
+		"720h":  true,
+		"1h30m": true,
+		"0s":    false,
+		"-1h":   false,
+		"30d":   false,
+	}
+
+	for value, expected := range testCases {
+		t.Run(value, func(t *testing.T) {
+			if actual := validateString(durationValidator{}, value); actual != expected {
+				t.Errorf("expected %t, got %t", expected, actual)
+			}
+		})
+	}
+}
+
+func TestCidrValidator(t *testing.T) {
+	testCases := map[string]bool{
+		"10.0.0.0/8":     true,
+		"192.168.1.10":   true,
+		"2001:db8::/32":  true,
+		"::1":            true,
+		"10.0.0.0/33":    false,
+		"example.com":    false,
+		"10.0.0.0/8,foo": false,
+	}
+
+	for value, expected := range testCases {
+		t.Run(value, func(t *testing.T) {
+			if actual := validateString(cidrValidator{}, value); actual != expected {
+				t.Errorf("expected %t, got %t", expected, actual)
+			}
+		})
+	}
+}
+
+func TestDatabaseNameValidator(t *testing.T) {
+	testCases := map[string]struct {
+		users bool
+		roles bool
+	}{
+		"test":                  {true, true},
+		"admin":                 {true, true},
+		"$external":             {true, false},
+		"local":                 {false, true},
+		"config":                {false, true},
+		"":                      {false, false},
+		"my.db":                 {false, false},
+		"my db":                 {false, false},
+		"my/db":                 {false, false},
+		"my$db":                 {false, false},
+		"db\x00":                {false, false},
+		strings.Repeat("a", 63): {true, true},
+		strings.Repeat("a", 64): {false, false},
+	}
+
+	for value, expected := range testCases {
+		t.Run(value, func(t *testing.T) {
+			if actual := validateString(databaseNameValidator{}, value); actual != expected.users {
+				t.Errorf("expected %t for users, got %t", expected.users, actual)
+			}
+			if actual := validateString(databaseNameValidator{roles: true}, value); actual != expected.roles {
+				t.Errorf("expected %t for roles, got %t", expected.roles, actual)
+			}
+		})
+	}
+}
+
+func TestUserNameValidator(t *testing.T) {
+	testCases := map[string]bool{
+		"app":              true,
+		"CN=app,O=Example": true,
+		"app@EXAMPLE.COM":  true,
+		"":                 false,
+		"app\x00":          false,
+	}
+
+	for value, expected := range testCases {
+		t.Run(value, func(t *testing.T) {
+			if actual := validateString(userNameValidator{}, value); actual != expected {
+				t.Errorf("expected %t, got %t", expected, actual)
+			}
+		})
+	}
+}
+
+func TestRoleNameValidator(t *testing.T) {
+	testCases := map[string]bool{
+		"readWrite": true,
+		"app-read":  true,
+		"":          false,
+		" read":     false,
+		"read ":     false,
+		"$read":     false,
+		"re\x00ad":  false,
+	}
+
+	for value, expected := range testCases {
+		t.Run(value, func(t *testing.T) {
+			if actual := validateString(roleNameValidator{}, value); actual != expected {
+				t.Errorf("expected %t, got %t", expected, actual)
+			}
+		})
+	}
+}